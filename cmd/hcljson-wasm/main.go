@@ -0,0 +1,33 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command hcljson-wasm exposes the converter to JavaScript when built as
+// WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o hcljson.wasm ./cmd/hcljson-wasm
+//
+// Once run, it defines the global function hcljsonConvert(src), which
+// returns {json} on success and {error} on failure.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/tmax-cloud/hcljson/convert"
+)
+
+func main() {
+	js.Global().Set("hcljsonConvert", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return map[string]interface{}{"error": "hcljsonConvert takes a single string"}
+		}
+		out, err := convert.ConvertString(args[0].String())
+		if err != nil {
+			return map[string]interface{}{"error": err.Error()}
+		}
+		return map[string]interface{}{"json": out}
+	}))
+
+	// keep the functions callable after main returns
+	select {}
+}
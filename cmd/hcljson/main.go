@@ -0,0 +1,220 @@
+// Command hcljson converts HCL to JSON, and JSON produced by it back to HCL.
+//
+//	hcljson [flags] [file]
+//	hcljson serve [-addr address]
+//
+// The input is read from the file, or from stdin if none is given. JSON
+// input is converted to HCL, anything else to JSON; .tfvars files are
+// checked to hold only constant assignments. With -sarif, the diagnostics
+// of a conversion to JSON are also written as SARIF, for code scanning.
+// With -var-file, references to the variables it assigns are replaced with
+// their values.
+//
+// The serve subcommand converts the bodies of POST /convert requests the
+// same way, taking the file name from the filename query parameter, and
+// responds with diagnostics as JSON on failure; conversions taking more
+// than 30 seconds are cancelled. It also serves the web playground on
+// /playground.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/tmax-cloud/hcljson/convert"
+	"github.com/tmax-cloud/hcljson/playground"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		serve(os.Args[2:])
+		return
+	}
+
+	output := flag.String("o", "", "write the result to `file` instead of stdout")
+	simplify := flag.Bool("simplify", false, "evaluate expressions that only depend on literals")
+	compact := flag.Bool("compact", false, "write JSON on a single line")
+	keepOrder := flag.Bool("keep-order", false, "keep the source order of blocks and attributes")
+	noColor := flag.Bool("no-color", false, "disable colors in diagnostics and logs")
+	verbose := flag.Bool("verbose", false, "log conversion steps to stderr")
+	sarif := flag.String("sarif", "", "also write the diagnostics of the conversion to `file` as SARIF")
+	var varFiles fileList
+	flag.Var(&varFiles, "var-file", "resolve var.* references with the values of a .tfvars or .tfvars.json `file`; may be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hcljson [flags] [file]\n       hcljson serve [-addr address]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	filename := "<stdin>"
+	var src []byte
+	var err error
+	if flag.NArg() == 1 {
+		filename = flag.Arg(0)
+		src, err = os.ReadFile(filename)
+	} else {
+		src, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	opts := convert.Options{Simplify: *simplify, PreserveOrder: *keepOrder}
+	for _, varFile := range varFiles {
+		if err := loadVarFile(&opts, varFile, !*noColor); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	if *verbose {
+		prefix := "\033[1;32mhcljson:\033[0m "
+		if *noColor {
+			prefix = "hcljson: "
+		}
+		opts.Logger = log.New(os.Stderr, prefix, 0)
+	}
+
+	var result []byte
+	if convert.DetectFormat(src, filename) == convert.FormatHCL {
+		result, err = toJSON(src, filename, opts, *compact, !*noColor, *sarif)
+	} else {
+		result, err = convert.JsonToNativeHcl(src)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if len(result) > 0 && result[len(result)-1] != '\n' {
+		result = append(result, '\n')
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(result)
+	} else {
+		err = os.WriteFile(*output, result, 0644)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func serve(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:8080", "listen on `address`")
+	simplify := flags.Bool("simplify", false, "evaluate expressions that only depend on literals")
+	flags.Parse(args)
+
+	mux := http.NewServeMux()
+	// the timeout cancels the context of the request, which stops the
+	// conversion; the write timeout of the server does not
+	mux.Handle("/convert", http.TimeoutHandler(convert.NewHandler(convert.Options{Simplify: *simplify}), 30*time.Second, "conversion timed out\n"))
+	mux.Handle("/playground", playground.Handler())
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      75 * time.Second,
+	}
+	fmt.Fprintf(os.Stderr, "hcljson: listening on %s\n", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func toJSON(src []byte, filename string, opts convert.Options, compact, color bool, sarif string) ([]byte, error) {
+	convertFunc := convert.ConvertWithOptions
+	if strings.HasSuffix(filename, ".tfvars") {
+		convertFunc = convert.ConvertTfvars
+	}
+	result, diags := convertFunc(src, filename, opts)
+	files := map[string]*hcl.File{filename: {Bytes: src}}
+	if len(diags) > 0 {
+		writer := hcl.NewDiagnosticTextWriter(os.Stderr, files, 78, color)
+		writer.WriteDiagnostics(diags)
+	}
+	if sarif != "" {
+		if err := writeSARIF(sarif, diags, files); err != nil {
+			return nil, err
+		}
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("conversion failed")
+	}
+	if compact {
+		return result, nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, result, "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fileList collects the values of a repeated flag.
+type fileList []string
+
+func (l *fileList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *fileList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// loadVarFile adds the values of filename to opts.Variables, later files
+// overriding earlier ones as in Terraform.
+func loadVarFile(opts *convert.Options, filename string, color bool) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	values, diags := convert.LoadVarFile(src, filename)
+	if len(diags) > 0 {
+		files := map[string]*hcl.File{filename: {Bytes: src}}
+		writer := hcl.NewDiagnosticTextWriter(os.Stderr, files, 78, color)
+		writer.WriteDiagnostics(diags)
+	}
+	if diags.HasErrors() {
+		return fmt.Errorf("unable to load %s", filename)
+	}
+	if opts.Variables == nil {
+		opts.Variables = make(map[string]cty.Value, len(values))
+	}
+	for name, value := range values {
+		opts.Variables[name] = value
+	}
+	return nil
+}
+
+func writeSARIF(filename string, diags hcl.Diagnostics, files map[string]*hcl.File) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := convert.WriteSARIF(f, diags, files); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func fatalf(format string, v ...interface{}) {
+	fmt.Fprintf(os.Stderr, "hcljson: "+format+"\n", v...)
+	os.Exit(1)
+}
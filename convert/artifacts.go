@@ -0,0 +1,211 @@
+package convert
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// Artifacts holds everything produced by a single conversion pass.
+type Artifacts struct {
+	// JSON is the encoded document, as returned by HclToJson, or in
+	// Options.Encoding if set.
+	JSON []byte
+	// Document is the converted document before encoding. It does not
+	// keep the key order of Options.PreserveOrder.
+	Document map[string]interface{}
+	// Diagnostics holds the parse and conversion diagnostics.
+	Diagnostics hcl.Diagnostics
+	// Skipped lists the content that was left out of the document.
+	Skipped []SkippedItem
+	// Sources maps the JSON pointer of every attribute to the raw source
+	// of its expression, if Options.CaptureSource is set. Attributes with
+	// anything redacted in their value are left out.
+	Sources map[string]string
+	// Injected marks the JSON pointers of attributes added from
+	// Options.Defaults.
+	Injected map[string]bool
+	// Ranges maps the JSON pointer of every block and attribute to its
+	// source range, if Options.CaptureRanges is set.
+	Ranges map[string]hcl.Range
+	// Report summarizes the conversion, if Options.Report is set.
+	Report *Report
+}
+
+// SkippedKind classifies a SkippedItem.
+type SkippedKind string
+
+const (
+	// SkippedExpression is an expression replaced with a placeholder.
+	SkippedExpression SkippedKind = "expression"
+	// SkippedRegion is source discarded by the parser on a syntax error.
+	SkippedRegion SkippedKind = "region"
+)
+
+// SkippedItem describes content that was left out of a converted document,
+// so audits can confirm nothing important was silently dropped.
+type SkippedItem struct {
+	Kind SkippedKind
+	// Address is the path of the value in the document, if it has one.
+	Address Path
+	Range   hcl.Range
+	Reason  string
+}
+
+// ConvertAll converts the input once and returns all artifacts of the
+// conversion. The error is non-nil when no document could be produced;
+// the returned Artifacts still carry the diagnostics in that case.
+func ConvertAll(bytes []byte, filename string, opts Options) (*Artifacts, error) {
+	return NewConverter(opts).ConvertAll(bytes, filename)
+}
+
+// ConvertAll is like the package-level ConvertAll, using c's options and
+// expression strategies.
+func (c *Converter) ConvertAll(bytes []byte, filename string) (*Artifacts, error) {
+	artifacts := c.convertAll(bytes, filename)
+	if artifacts.JSON == nil {
+		return artifacts, artifacts.Diagnostics
+	}
+
+	return artifacts, nil
+}
+
+func (c *Converter) convertAll(src []byte, filename string) *Artifacts {
+	key, cacheable := c.cacheKey(src, filename)
+	if c.opts.Cache == nil || !cacheable {
+		return c.convertUncached(src, filename)
+	}
+
+	if artifacts, ok := c.opts.Cache.Get(key); ok {
+		return artifacts
+	}
+	artifacts := c.convertUncached(src, filename)
+	// a cancelled conversion says nothing about the input
+	if c.ctx == nil || c.ctx.Err() == nil {
+		c.opts.Cache.Put(key, artifacts)
+	}
+	return artifacts
+}
+
+func (c *Converter) convertUncached(src []byte, filename string) *Artifacts {
+	artifacts := c.convertDocument(src, filename)
+	if artifacts.Document == nil {
+		return artifacts
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := c.encodeDocument(buffer, artifacts.Document); err != nil {
+		artifacts.Diagnostics = append(artifacts.Diagnostics, errorDiagnostic(err))
+		return artifacts
+	}
+	artifacts.JSON = buffer.Bytes()
+	if artifacts.Report != nil {
+		artifacts.Report.OutputBytes = len(artifacts.JSON)
+	}
+
+	return artifacts
+}
+
+// convertDocument converts src up to, but not including, encoding. The
+// Document of the returned artifacts is nil if the conversion failed.
+func (c *Converter) convertDocument(src []byte, filename string) *Artifacts {
+	artifacts := &Artifacts{}
+	if DetectFormat(src, filename) == FormatHCL {
+		if diag := c.checkNesting(src, filename); diag != nil {
+			artifacts.Diagnostics = hcl.Diagnostics{diag}
+			return artifacts
+		}
+	}
+
+	file, diags := parseFile(src, filename)
+	artifacts.Diagnostics = diags
+	if diags.HasErrors() && !c.opts.Tolerant {
+		return artifacts
+	}
+
+	convertedFile, err := c.convertFile(file, diags)
+	if err != nil {
+		artifacts.Diagnostics = append(artifacts.Diagnostics, errorDiagnostic(err))
+		return artifacts
+	}
+	artifacts.Document = convertedFile
+	artifacts.Diagnostics = append(artifacts.Diagnostics, c.problems()...)
+	if c.opts.CaptureSource {
+		artifacts.Sources = c.sources
+	}
+	if c.opts.CaptureRanges {
+		artifacts.Ranges = c.ranges
+	}
+	if len(c.opts.Defaults) > 0 {
+		artifacts.Injected = c.injected
+	}
+	if c.opts.Report {
+		artifacts.Report = c.finishReport(file)
+	}
+	artifacts.Skipped = append(c.skipped, c.skippedRegions()...)
+	sort.SliceStable(artifacts.Skipped, func(i, j int) bool {
+		return artifacts.Skipped[i].Range.Start.Byte < artifacts.Skipped[j].Range.Start.Byte
+	})
+
+	return artifacts
+}
+
+// problems returns the collected errors and the warnings of the conversion
+// in source order.
+func (c *Converter) problems() hcl.Diagnostics {
+	problems := append(c.errors, c.warnings...)
+	sort.SliceStable(problems, func(i, j int) bool {
+		// errors without a subject go last
+		if problems[i].Subject == nil || problems[j].Subject == nil {
+			return problems[j].Subject == nil && problems[i].Subject != nil
+		}
+		return problems[i].Subject.Start.Byte < problems[j].Subject.Start.Byte
+	})
+	return problems
+}
+
+// encodeDocument writes the converted document to w in Options.Encoding.
+func (c *Converter) encodeDocument(w io.Writer, convertedFile jsonObj) error {
+	if c.opts.Encoding == EncodingJSON {
+		return c.encodeJSONDocument(w, convertedFile)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := c.encodeJSONDocument(buffer, convertedFile); err != nil {
+		return err
+	}
+	out, err := transcode(c.opts.Encoding, buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(out); err != nil {
+		return newError(CodeEncodeFailed, nil, "write document", err)
+	}
+	return nil
+}
+
+func (c *Converter) encodeJSONDocument(w io.Writer, convertedFile jsonObj) error {
+	if !c.opts.PreserveOrder && !c.opts.Canonical {
+		return jsonEncoder(w, convertedFile)
+	}
+
+	var jsonBytes []byte
+	var err error
+	if c.opts.Canonical {
+		jsonBytes, err = encodeJSON(convertedFile)
+		if err == nil {
+			jsonBytes, err = canonicalJSON(jsonBytes)
+		}
+	} else {
+		jsonBytes, err = encodeOrdered(convertedFile, c.keyOrder)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(jsonBytes); err != nil {
+		return newError(CodeEncodeFailed, nil, "write json", err)
+	}
+	return nil
+}
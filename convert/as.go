@@ -0,0 +1,14 @@
+//go:build go1.18
+// +build go1.18
+
+package convert
+
+// As converts the input and unmarshals the resulting JSON into a value of
+// type T in one step.
+//
+// MEMO : gopherjs 빌드는 go1.18 이전 문법만 지원하므로 build tag로 분리함.
+func As[T any](bytes []byte, filename string, opts Options) (T, error) {
+	var out T
+	err := decode(bytes, filename, opts, &out)
+	return out, err
+}
@@ -0,0 +1,170 @@
+package convert
+
+import (
+	"fmt"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ExprMode selects how expressions that are not literals are represented
+// in the output.
+type ExprMode int
+
+const (
+	// ExprModeTemplate wraps expressions as "${...}" strings.
+	ExprModeTemplate ExprMode = iota
+	// ExprModeAST emits expressions as their syntax tree, one object per
+	// node with its "type", "range" and operands, for static analysis.
+	ExprModeAST
+)
+
+var operatorSymbols = map[*hclsyntax.Operation]string{
+	hclsyntax.OpLogicalOr:          "||",
+	hclsyntax.OpLogicalAnd:         "&&",
+	hclsyntax.OpLogicalNot:         "!",
+	hclsyntax.OpEqual:              "==",
+	hclsyntax.OpNotEqual:           "!=",
+	hclsyntax.OpGreaterThan:        ">",
+	hclsyntax.OpGreaterThanOrEqual: ">=",
+	hclsyntax.OpLessThan:           "<",
+	hclsyntax.OpLessThanOrEqual:    "<=",
+	hclsyntax.OpAdd:                "+",
+	hclsyntax.OpSubtract:           "-",
+	hclsyntax.OpMultiply:           "*",
+	hclsyntax.OpDivide:             "/",
+	hclsyntax.OpModulo:             "%",
+	hclsyntax.OpNegate:             "-",
+}
+
+// isLiteralExpr reports whether expr keeps its plain JSON form in
+// ExprModeAST. Tuples and objects stay arrays and objects, with their
+// elements converted on their own.
+func isLiteralExpr(expr hclsyntax.Expression) bool {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr, *hclsyntax.TupleConsExpr, *hclsyntax.ObjectConsExpr:
+		return true
+	case *hclsyntax.TemplateExpr:
+		return e.IsStringLiteral()
+	case *hclsyntax.UnaryOpExpr:
+		// negative numbers such as -1
+		_, ok := e.Val.(*hclsyntax.LiteralValueExpr)
+		return ok
+	}
+	return false
+}
+
+// exprAST returns the syntax tree of expr. Within the tree every operand
+// is a node, literals included.
+func (c *Converter) exprAST(expr hclsyntax.Expression) (jsonObj, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	node := jsonObj{
+		"type":  strings.TrimPrefix(fmt.Sprintf("%T", expr), "*hclsyntax."),
+		"range": rangeObject(expr.Range()),
+	}
+	var err error
+	operand := func(name string, e hclsyntax.Expression) {
+		if err != nil || e == nil {
+			return
+		}
+		node[name], err = c.exprAST(e)
+	}
+	operands := func(name string, exprs []hclsyntax.Expression) {
+		list := make([]interface{}, 0, len(exprs))
+		for _, e := range exprs {
+			if err != nil {
+				return
+			}
+			var item jsonObj
+			item, err = c.exprAST(e)
+			list = append(list, item)
+		}
+		node[name] = list
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		node["value"], err = c.literal(e.Val, e.SrcRange)
+	case *hclsyntax.TemplateExpr:
+		operands("parts", e.Parts)
+	case *hclsyntax.TemplateWrapExpr:
+		operand("wrapped", e.Wrapped)
+	case *hclsyntax.TemplateJoinExpr:
+		operand("tuple", e.Tuple)
+	case *hclsyntax.ScopeTraversalExpr:
+		node["traversal"] = c.referenceObject(e)[referenceKey]
+	case *hclsyntax.RelativeTraversalExpr:
+		operand("source", e.Source)
+		node["traversal"] = c.referenceObject(&hclsyntax.ScopeTraversalExpr{Traversal: e.Traversal})[referenceKey]
+	case *hclsyntax.FunctionCallExpr:
+		node["name"] = e.Name
+		node["expand_final"] = e.ExpandFinal
+		operands("args", e.Args)
+	case *hclsyntax.IndexExpr:
+		operand("collection", e.Collection)
+		operand("key", e.Key)
+	case *hclsyntax.SplatExpr:
+		operand("source", e.Source)
+		operand("each", e.Each)
+	case *hclsyntax.BinaryOpExpr:
+		node["operator"] = operatorSymbols[e.Op]
+		operand("lhs", e.LHS)
+		operand("rhs", e.RHS)
+	case *hclsyntax.UnaryOpExpr:
+		node["operator"] = operatorSymbols[e.Op]
+		operand("operand", e.Val)
+	case *hclsyntax.ConditionalExpr:
+		operand("condition", e.Condition)
+		operand("true", e.TrueResult)
+		operand("false", e.FalseResult)
+	case *hclsyntax.ParenthesesExpr:
+		operand("expression", e.Expression)
+	case *hclsyntax.ForExpr:
+		node["value_var"] = e.ValVar
+		if e.KeyVar != "" {
+			node["key_var"] = e.KeyVar
+		}
+		node["group"] = e.Group
+		operand("collection", e.CollExpr)
+		operand("key", e.KeyExpr)
+		operand("value", e.ValExpr)
+		operand("condition", e.CondExpr)
+	case *hclsyntax.TupleConsExpr:
+		operands("exprs", e.Exprs)
+	case *hclsyntax.ObjectConsExpr:
+		items := make([]interface{}, 0, len(e.Items))
+		for _, item := range e.Items {
+			key, keyErr := c.exprAST(item.KeyExpr)
+			value, valueErr := c.exprAST(item.ValueExpr)
+			if keyErr != nil {
+				return nil, keyErr
+			}
+			if valueErr != nil {
+				return nil, valueErr
+			}
+			items = append(items, jsonObj{"key": key, "value": value})
+		}
+		node["items"] = items
+	case *hclsyntax.ObjectConsKeyExpr:
+		node["force_non_literal"] = e.ForceNonLiteral
+		operand("wrapped", e.Wrapped)
+	case *hclsyntax.AnonSymbolExpr:
+	default:
+		node["source"] = c.rangeSource(expr.Range())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func rangeObject(r hcl.Range) jsonObj {
+	pos := func(p hcl.Pos) jsonObj {
+		return jsonObj{"line": p.Line, "column": p.Column, "byte": p.Byte}
+	}
+	return jsonObj{"filename": r.Filename, "start": pos(r.Start), "end": pos(r.End)}
+}
@@ -0,0 +1,85 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+)
+
+// Format is the syntax of a conversion input.
+type Format int
+
+const (
+	// FormatHCL is the HCL native syntax.
+	FormatHCL Format = iota
+	// FormatHCLJSON is the HCL JSON syntax, e.g. a .tf.json file.
+	FormatHCLJSON
+	// FormatJSON is plain JSON that is not meant to be read as HCL.
+	FormatJSON
+)
+
+var hclJSONSuffixes = []string{".tf.json", ".hcl.json", ".tfvars.json"}
+
+// DetectFormat reports the syntax of the input. The file extension decides
+// when it is conclusive, otherwise the content is sniffed.
+func DetectFormat(src []byte, filename string) Format {
+	for _, suffix := range hclJSONSuffixes {
+		if strings.HasSuffix(filename, suffix) {
+			return FormatHCLJSON
+		}
+	}
+	if strings.HasSuffix(filename, ".json") {
+		return FormatJSON
+	}
+
+	// MEMO : native syntax body는 '{' 나 '['로 시작할 수 없으므로 첫 글자로 판별함.
+	trimmed := bytes.TrimSpace(src)
+	if len(trimmed) == 0 || !json.Valid(trimmed) {
+		return FormatHCL
+	}
+	if trimmed[0] == '{' {
+		return FormatHCLJSON
+	}
+	return FormatJSON
+}
+
+// Auto converts native HCL to JSON like HclToJson, and normalizes input that
+// is already JSON (HCL JSON syntax or plain JSON) to the same encoding, so
+// callers with mixed .tf/.tf.json trees can use one entry point.
+func Auto(src []byte, filename string) ([]byte, error) {
+	switch DetectFormat(src, filename) {
+	case FormatHCLJSON:
+		if _, diags := hcljson.Parse(src, filename); diags.HasErrors() {
+			return nil, newError(CodeSyntaxError, diags[0].Subject, "parse config", diags)
+		}
+		return normalizeJSON(src)
+	case FormatJSON:
+		return normalizeJSON(src)
+	default:
+		return HclToJson(src, filename)
+	}
+}
+
+func normalizeJSON(src []byte) ([]byte, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, newError(CodeInvalidJSON, nil, "decode json", err)
+	}
+
+	return encodeJSON(value)
+}
+
+// parseFile parses src in native or HCL JSON syntax, as told by
+// DetectFormat.
+func parseFile(src []byte, filename string) (*hcl.File, hcl.Diagnostics) {
+	if DetectFormat(src, filename) == FormatHCLJSON {
+		return hcljson.Parse(src, filename)
+	}
+	return hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+}
@@ -0,0 +1,81 @@
+package convert
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchmarkModule returns the source of a module with resources of the
+// usual kinds of attributes, nested blocks and expressions.
+func benchmarkModule(resources int) []byte {
+	var src strings.Builder
+	src.WriteString(`variable "env" {
+  type    = string
+  default = "dev"
+}
+
+locals {
+  tags = { Environment = var.env, Team = "platform" }
+}
+`)
+	for i := 0; i < resources; i++ {
+		fmt.Fprintf(&src, `
+resource "aws_instance" "web_%d" {
+  ami           = "ami-%08d"
+  instance_type = var.env == "prod" ? "m5.large" : "t3.micro"
+  count         = 2
+  subnet_id     = aws_subnet.main[count.index %% 2].id
+  user_data     = "#!/bin/sh\necho ${var.env}-%d"
+  tags          = merge(local.tags, { Name = "web-${count.index}" })
+
+  ebs_block_device {
+    device_name = "/dev/sdb"
+    volume_size = 100
+  }
+
+  ebs_block_device {
+    device_name = "/dev/sdc"
+    volume_size = 200
+  }
+
+  lifecycle {
+    create_before_destroy = true
+    ignore_changes        = [tags]
+  }
+}
+`, i, i, i)
+	}
+	return []byte(src.String())
+}
+
+func benchmarkConvert(b *testing.B, src []byte, opts Options) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, diags := ConvertWithOptions(src, "main.tf", opts); diags.HasErrors() {
+			b.Fatal(diags)
+		}
+	}
+}
+
+func BenchmarkConvertSmall(b *testing.B) {
+	benchmarkConvert(b, benchmarkModule(10), Options{})
+}
+
+func BenchmarkConvertLarge(b *testing.B) {
+	benchmarkConvert(b, benchmarkModule(10000), Options{})
+}
+
+func BenchmarkConvertSimplify(b *testing.B) {
+	benchmarkConvert(b, benchmarkModule(100), Options{Simplify: true})
+}
+
+func BenchmarkConvertTerraformMode(b *testing.B) {
+	benchmarkConvert(b, benchmarkModule(100), Options{TerraformMode: true})
+}
+
+func BenchmarkConvertPreserveOrder(b *testing.B) {
+	benchmarkConvert(b, benchmarkModule(100), Options{PreserveOrder: true})
+}
@@ -0,0 +1,219 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// jsonToMsgpack re-encodes a JSON document as MessagePack. Integers use the
+// smallest int or uint format that holds them and other numbers float64;
+// integers beyond 64 bits, which MessagePack cannot hold, become strings.
+func jsonToMsgpack(jsonBytes []byte) ([]byte, error) {
+	value, err := decodeOrdered(jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeMsgpack(&buf, value)
+	return buf.Bytes(), nil
+}
+
+func writeMsgpack(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		writeMsgpackNumber(buf, v)
+	case string:
+		writeMsgpackHead(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		buf.WriteString(v)
+	case []interface{}:
+		writeMsgpackHead(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			writeMsgpack(buf, item)
+		}
+	case *orderedObject:
+		writeMsgpackHead(buf, len(v.keys), 0x80, 16, 0, 0xde, 0xdf)
+		for i, key := range v.keys {
+			writeMsgpack(buf, key)
+			writeMsgpack(buf, v.values[i])
+		}
+	}
+}
+
+// writeMsgpackHead writes the header of a string, array or map of length n:
+// the fix format below fixLimit, then the 8, 16 or 32-bit length formats.
+// A zero format8 means the type has no 8-bit length format.
+func writeMsgpackHead(buf *bytes.Buffer, n int, fix byte, fixLimit int, format8, format16, format32 byte) {
+	switch {
+	case n < fixLimit:
+		buf.WriteByte(fix | byte(n))
+	case format8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(format8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(format16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(format32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// writeMsgpackNumber writes a non-negative integer in a positive fixint or
+// a uint format and a negative one in a negative fixint or an int format.
+func writeMsgpackNumber(buf *bytes.Buffer, number json.Number) {
+	if u, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+		switch {
+		case u < 128:
+			buf.WriteByte(byte(u))
+		case u <= math.MaxUint8:
+			buf.WriteByte(0xcc)
+			buf.WriteByte(byte(u))
+		case u <= math.MaxUint16:
+			buf.WriteByte(0xcd)
+			binary.Write(buf, binary.BigEndian, uint16(u))
+		case u <= math.MaxUint32:
+			buf.WriteByte(0xce)
+			binary.Write(buf, binary.BigEndian, uint32(u))
+		default:
+			buf.WriteByte(0xcf)
+			binary.Write(buf, binary.BigEndian, u)
+		}
+		return
+	}
+	if i, err := strconv.ParseInt(number.String(), 10, 64); err == nil {
+		switch {
+		case i >= -32:
+			buf.WriteByte(byte(i))
+		case i >= math.MinInt8:
+			buf.WriteByte(0xd0)
+			buf.WriteByte(byte(i))
+		case i >= math.MinInt16:
+			buf.WriteByte(0xd1)
+			binary.Write(buf, binary.BigEndian, int16(i))
+		case i >= math.MinInt32:
+			buf.WriteByte(0xd2)
+			binary.Write(buf, binary.BigEndian, int32(i))
+		default:
+			buf.WriteByte(0xd3)
+			binary.Write(buf, binary.BigEndian, i)
+		}
+		return
+	}
+	if _, ok := new(big.Int).SetString(number.String(), 10); ok {
+		writeMsgpack(buf, number.String())
+		return
+	}
+	f, _ := strconv.ParseFloat(number.String(), 64)
+	buf.WriteByte(0xcb)
+	binary.Write(buf, binary.BigEndian, f)
+}
+
+// jsonToCBOR re-encodes a JSON document as CBOR (RFC 8949). Integers are
+// exact, beyond 64 bits as bignums, and other numbers float64.
+func jsonToCBOR(jsonBytes []byte) ([]byte, error) {
+	value, err := decodeOrdered(jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeCBOR(&buf, value)
+	return buf.Bytes(), nil
+}
+
+const (
+	cborUnsigned = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+	cborTag      = 6
+)
+
+func writeCBOR(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if v {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case json.Number:
+		writeCBORNumber(buf, v)
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			writeCBOR(buf, item)
+		}
+	case *orderedObject:
+		writeCBORHead(buf, cborMap, uint64(len(v.keys)))
+		for i, key := range v.keys {
+			writeCBOR(buf, key)
+			writeCBOR(buf, v.values[i])
+		}
+	}
+}
+
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func writeCBORNumber(buf *bytes.Buffer, number json.Number) {
+	i, ok := new(big.Int).SetString(number.String(), 10)
+	if !ok {
+		f, _ := strconv.ParseFloat(number.String(), 64)
+		buf.WriteByte(0xfb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+		return
+	}
+
+	// a negative integer n is encoded as -1 - n
+	major, tag := byte(cborUnsigned), uint64(2)
+	if i.Sign() < 0 {
+		major, tag = cborNegative, 3
+		i.Neg(i).Sub(i, big.NewInt(1))
+	}
+	if i.IsUint64() {
+		writeCBORHead(buf, major, i.Uint64())
+		return
+	}
+	writeCBORHead(buf, cborTag, tag)
+	magnitude := i.Bytes()
+	writeCBORHead(buf, cborBytes, uint64(len(magnitude)))
+	buf.Write(magnitude)
+}
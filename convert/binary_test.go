@@ -0,0 +1,153 @@
+package convert
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+	ctymsgpack "github.com/zclconf/go-cty/cty/msgpack"
+)
+
+func TestJSONToMsgpackIntegers(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"0", "00"},
+		{"127", "7f"},
+		{"128", "cc80"},
+		{"255", "ccff"},
+		{"256", "cd0100"},
+		{"65535", "cdffff"},
+		{"65536", "ce00010000"},
+		{"4294967295", "ceffffffff"},
+		{"4294967296", "cf0000000100000000"},
+		{"18446744073709551615", "cfffffffffffffffff"},
+		{"-1", "ff"},
+		{"-32", "e0"},
+		{"-33", "d0df"},
+		{"-128", "d080"},
+		{"-129", "d1ff7f"},
+		{"-32768", "d18000"},
+		{"-32769", "d2ffff7fff"},
+		{"-2147483648", "d280000000"},
+		{"-2147483649", "d3ffffffff7fffffff"},
+		{"-9223372036854775808", "d38000000000000000"},
+		{"18446744073709551616", "b4" + hex.EncodeToString([]byte("18446744073709551616"))},
+		{"1.5", "cb3ff8000000000000"},
+		{"1e3", "cb408f400000000000"},
+	}
+	for _, test := range tests {
+		t.Run(test.number, func(t *testing.T) {
+			got, err := jsonToMsgpack([]byte(test.number))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != test.want {
+				t.Errorf("got %x, want %s", got, test.want)
+			}
+			value, err := ctymsgpack.Unmarshal(got, cty.Number)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := cty.MustParseNumberVal(test.number); !value.RawEquals(want) {
+				t.Errorf("decoded %#v, want %#v", value, want)
+			}
+		})
+	}
+}
+
+func TestJSONToMsgpackLengths(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "empty", json: `{"a":"","b":[],"c":{}}`, want: "83a161a0a16290a16380"},
+		{name: "literals", json: `[null,true,false]`, want: "93c0c3c2"},
+		{name: "fixstr", json: `"` + strings.Repeat("x", 31) + `"`, want: "bf" + strings.Repeat("78", 31)},
+		{name: "str8", json: `"` + strings.Repeat("x", 32) + `"`, want: "d920" + strings.Repeat("78", 32)},
+		{name: "str16", json: `"` + strings.Repeat("x", 256) + `"`, want: "da0100" + strings.Repeat("78", 256)},
+		{name: "fixarray", json: "[" + strings.Repeat("1,", 14) + "1]", want: "9f" + strings.Repeat("01", 15)},
+		{name: "array16", json: "[" + strings.Repeat("1,", 15) + "1]", want: "dc0010" + strings.Repeat("01", 16)},
+		{name: "key order", json: `{"b":1,"a":2}`, want: "82a16201a16102"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := jsonToMsgpack([]byte(test.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != test.want {
+				t.Errorf("got %x, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+// The expected bytes are the examples of RFC 8949, appendix A.
+func TestJSONToCBOR(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{"0", "00"},
+		{"23", "17"},
+		{"24", "1818"},
+		{"100", "1864"},
+		{"1000", "1903e8"},
+		{"1000000", "1a000f4240"},
+		{"1000000000000", "1b000000e8d4a51000"},
+		{"18446744073709551615", "1bffffffffffffffff"},
+		{"18446744073709551616", "c249010000000000000000"},
+		{"-18446744073709551616", "3bffffffffffffffff"},
+		{"-18446744073709551617", "c349010000000000000000"},
+		{"-1", "20"},
+		{"-10", "29"},
+		{"-100", "3863"},
+		{"-1000", "3903e7"},
+		{"1.1", "fb3ff199999999999a"},
+		{"1.0e+300", "fb7e37e43c8800759c"},
+		{"-4.1", "fbc010666666666666"},
+		{"false", "f4"},
+		{"true", "f5"},
+		{"null", "f6"},
+		{`""`, "60"},
+		{`"a"`, "6161"},
+		{`"IETF"`, "6449455446"},
+		{`"ü"`, "62c3bc"},
+		{"[]", "80"},
+		{"[1,2,3]", "83010203"},
+		{"[1,[2,3],[4,5]]", "8301820203820405"},
+		{"[" + strings.TrimSuffix(strings.Repeat("1,", 25), ",") + "]", "9819" + strings.Repeat("01", 25)},
+		{"{}", "a0"},
+		{`{"a":1,"b":[2,3]}`, "a26161016162820203"},
+		{`{"a":"A","b":"B","c":"C","d":"D","e":"E"}`, "a56161614161626142616361436164614461656145"},
+	}
+	for _, test := range tests {
+		t.Run(test.json, func(t *testing.T) {
+			got, err := jsonToCBOR([]byte(test.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != test.want {
+				t.Errorf("got %x, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHclToMsgpack(t *testing.T) {
+	artifacts, err := ConvertAll([]byte("a = 300\nb = \"x\"\n"), "main.tf", Options{Encoding: EncodingMsgpack})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := ctymsgpack.Unmarshal(artifacts.JSON, cty.Object(map[string]cty.Type{"a": cty.Number, "b": cty.String}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := cty.ObjectVal(map[string]cty.Value{"a": cty.NumberIntVal(300), "b": cty.StringVal("x")}); !value.RawEquals(want) {
+		t.Errorf("got %#v, want %#v", value, want)
+	}
+}
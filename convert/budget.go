@@ -0,0 +1,161 @@
+package convert
+
+import (
+	"fmt"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// DefaultMaxExpansion is the bound on the elements evaluation expands to in
+// a file when Options.MaxExpansion is zero.
+const DefaultMaxExpansion = 100000
+
+func (c *Converter) checkResourceBudget(body *hclsyntax.Body) {
+	if c.opts.MaxResourcesPerFile <= 0 {
+		return
+	}
+
+	count := 0
+	for _, block := range body.Blocks {
+		if block.Type != "resource" {
+			continue
+		}
+		count++
+		if count == c.opts.MaxResourcesPerFile+1 {
+			r := block.DefRange()
+			c.warnings = append(c.warnings, warningDiagnostic(CodeBudgetExceeded, &r,
+				fmt.Sprintf("The file declares more than %d resources.", c.opts.MaxResourcesPerFile)))
+		}
+	}
+}
+
+func (c *Converter) checkValueBudget(attr *hclsyntax.Attribute) {
+	if c.opts.MaxAttributeValueSize <= 0 {
+		return
+	}
+
+	r := attr.Expr.Range()
+	if size := r.End.Byte - r.Start.Byte; size > c.opts.MaxAttributeValueSize {
+		c.warnings = append(c.warnings, warningDiagnostic(CodeBudgetExceeded, &r,
+			fmt.Sprintf("The value of %s is %d bytes, more than the limit of %d.", c.path, size, c.opts.MaxAttributeValueSize)))
+	}
+}
+
+// expansionLimit returns the bound on the elements evaluation may expand to
+// in a file, or 0 if there is none.
+func (c *Converter) expansionLimit() int {
+	switch {
+	case c.opts.MaxExpansion > 0:
+		return c.opts.MaxExpansion
+	case c.opts.MaxExpansion < 0:
+		return 0
+	}
+	return DefaultMaxExpansion
+}
+
+// charge counts n more elements expanded by evaluation, failing once the
+// file expanded more than expansionLimit or the context of c is done.
+func (c *Converter) charge(n int) error {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return c.ctx.Err()
+	}
+	limit := c.expansionLimit()
+	if limit == 0 {
+		return nil
+	}
+	c.expanded += n
+	if c.expanded > limit {
+		return fmt.Errorf("evaluation expands to more than %d elements", limit)
+	}
+	return nil
+}
+
+// warnExpansion reports that evaluating expr exceeded expansionLimit, if
+// the file had expanded before elements before it.
+func (c *Converter) warnExpansion(expr hclsyntax.Expression, before int) {
+	limit := c.expansionLimit()
+	if limit == 0 || before > limit || c.expanded <= limit {
+		return
+	}
+	r := expr.Range()
+	c.warnings = append(c.warnings, warningDiagnostic(CodeBudgetExceeded, &r,
+		fmt.Sprintf("Evaluation expands to more than %d elements, so expressions expanding further are left unsimplified.", limit)))
+}
+
+// chargedFunc returns fn charging the elements of the collections it
+// returns, and failing once the context of c is done.
+func (c *Converter) chargedFunc(fn function.Function) function.Function {
+	return function.New(&function.Spec{
+		Params:   fn.Params(),
+		VarParam: fn.VarParam(),
+		Type:     fn.ReturnTypeForValues,
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if err := c.charge(0); err != nil {
+				return cty.UnknownVal(retType), err
+			}
+			val, err := fn.Call(args)
+			if err != nil {
+				return val, err
+			}
+			if val.IsKnown() && !val.IsNull() && val.CanIterateElements() {
+				if err := c.charge(val.LengthInt()); err != nil {
+					return cty.UnknownVal(retType), err
+				}
+			}
+			return val, nil
+		},
+	})
+}
+
+// chargeIterations makes the for expressions of expr charge the elements
+// they iterate over, by wrapping their collections until the returned
+// function is called. It is the only way to count the iterations, which
+// evaluation does not expose otherwise.
+func (c *Converter) chargeIterations(expr hcl.Expression) func() {
+	native, ok := expr.(hclsyntax.Expression)
+	if !ok || c.expansionLimit() == 0 {
+		return func() {}
+	}
+	var fors []*hclsyntax.ForExpr
+	hclsyntax.VisitAll(native, func(node hclsyntax.Node) hcl.Diagnostics {
+		if forExpr, ok := node.(*hclsyntax.ForExpr); ok {
+			fors = append(fors, forExpr)
+		}
+		return nil
+	})
+	for _, forExpr := range fors {
+		forExpr.CollExpr = &chargedExpr{Expression: forExpr.CollExpr, c: c}
+	}
+	return func() {
+		for _, forExpr := range fors {
+			forExpr.CollExpr = forExpr.CollExpr.(*chargedExpr).Expression
+		}
+	}
+}
+
+// chargedExpr is the collection of a for expression, charging its elements
+// as the iterations of the for expression.
+type chargedExpr struct {
+	hclsyntax.Expression
+	c *Converter
+}
+
+func (e *chargedExpr) Value(ctx *hcl.EvalContext) (cty.Value, hcl.Diagnostics) {
+	val, diags := e.Expression.Value(ctx)
+	if diags.HasErrors() || !val.IsKnown() || val.IsNull() || !val.CanIterateElements() {
+		return val, diags
+	}
+	if err := e.c.charge(val.LengthInt()); err != nil {
+		r := e.Range()
+		return cty.DynamicVal, append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Expansion budget exceeded",
+			Detail:   err.Error(),
+			Subject:  &r,
+		})
+	}
+	return val, diags
+}
@@ -0,0 +1,110 @@
+package convert
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Cache stores the artifacts of conversions by key, for Options.Cache. It
+// must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*Artifacts, bool)
+	Put(key string, artifacts *Artifacts)
+}
+
+// cacheKey identifies a conversion by the SHA-256 of its input, file name
+// and options. Only options made of plain values can be told apart by
+// their printed form, so conversions with functions, pointers or
+// interfaces in their options, such as hooks, an EvalContext or
+// Variables, or with expression strategies, are not cacheable. The Logger
+// leaves the output alone and is not part of the key.
+func (c *Converter) cacheKey(src []byte, filename string) (key string, cacheable bool) {
+	opts := c.opts
+	opts.Cache, opts.Logger = nil, nil
+	if len(c.strategies) > 0 || !plainValue(reflect.ValueOf(opts)) {
+		return "", false
+	}
+
+	hash := sha256.New()
+	hash.Write(src)
+	fmt.Fprintf(hash, "\x00%s\x00%#v", filename, opts)
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// plainValue reports whether v holds no functions, pointers, interfaces or
+// channels, nil ones aside, so that its %#v form stands for its content.
+func plainValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Func, reflect.Ptr, reflect.Interface, reflect.Chan, reflect.UnsafePointer:
+		return v.IsNil()
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !plainValue(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !plainValue(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if !plainValue(iter.Key()) || !plainValue(iter.Value()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NewLRUCache returns an in-memory Cache keeping the size most recently used
+// conversions.
+func NewLRUCache(size int) Cache {
+	return &lruCache{size: size, entries: make(map[string]*list.Element)}
+}
+
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	order   list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	artifacts *Artifacts
+}
+
+func (l *lruCache) Get(key string) (*Artifacts, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry).artifacts, true
+}
+
+func (l *lruCache) Put(key string, artifacts *Artifacts) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if element, ok := l.entries[key]; ok {
+		element.Value.(*lruEntry).artifacts = artifacts
+		l.order.MoveToFront(element)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key, artifacts})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
@@ -0,0 +1,72 @@
+package convert
+
+import (
+	"testing"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type countingCache struct {
+	hits, puts int
+	entries    map[string]*Artifacts
+}
+
+func (c *countingCache) Get(key string) (*Artifacts, bool) {
+	artifacts, ok := c.entries[key]
+	if ok {
+		c.hits++
+	}
+	return artifacts, ok
+}
+
+func (c *countingCache) Put(key string, artifacts *Artifacts) {
+	c.puts++
+	c.entries[key] = artifacts
+}
+
+func TestCacheBypass(t *testing.T) {
+	src := []byte(`a = var.x`)
+	tests := []struct {
+		name      string
+		opts      Options
+		cacheable bool
+	}{
+		{"plain", Options{Redact: []string{"a"}, BlockShapes: map[string]BlockShape{"a": BlockShapeArray}}, true},
+		{"variables", Options{Variables: map[string]cty.Value{"x": cty.StringVal("y")}}, false},
+		{"eval context", Options{EvalContext: &hcl.EvalContext{}}, false},
+		{"hook", Options{AfterAttribute: func(p Path, v interface{}) (interface{}, bool) { return v, true }}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := &countingCache{entries: make(map[string]*Artifacts)}
+			test.opts.Cache = cache
+			for i := 0; i < 2; i++ {
+				if _, err := ConvertAll(src, "main.tf", test.opts); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := cache.puts == 1 && cache.hits == 1; got != test.cacheable {
+				t.Errorf("got %d puts and %d hits, want cacheable %v", cache.puts, cache.hits, test.cacheable)
+			}
+			if !test.cacheable && cache.puts+cache.hits != 0 {
+				t.Errorf("got %d puts and %d hits, want none", cache.puts, cache.hits)
+			}
+		})
+	}
+}
+
+func TestCacheStrategiesBypass(t *testing.T) {
+	cache := &countingCache{entries: make(map[string]*Artifacts)}
+	c := NewConverter(Options{Cache: cache})
+	c.Handle(&hclsyntax.ScopeTraversalExpr{}, func(c *Converter, expr hclsyntax.Expression) (interface{}, error) {
+		return "handled", nil
+	})
+	if _, err := c.ConvertAll([]byte(`a = var.x`), "main.tf"); err != nil {
+		t.Fatal(err)
+	}
+	if cache.puts != 0 {
+		t.Errorf("got %d puts, want none", cache.puts)
+	}
+}
@@ -0,0 +1,94 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// canonicalJSON re-encodes src with sorted keys, no insignificant
+// whitespace, no trailing newline and numbers in a canonical form, so that
+// equal documents always encode to the same bytes. This also covers raw
+// JSON returned by Options.FormatNumber and Options.ValueEncoder.
+func canonicalJSON(src []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "canonicalize json", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "canonicalize json", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeValue(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case json.Number:
+		n, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(n)
+		return nil
+	}
+	return writeValue(buf, value)
+}
+
+// canonicalNumber formats n without exponent, trailing fractional zeros or
+// negative zero, e.g. 1.50 as 1.5 and 1e3 as 1000.
+func canonicalNumber(n json.Number) (string, error) {
+	f, _, err := big.ParseFloat(string(n), 10, 512, big.ToNearestEven)
+	if err != nil {
+		return "", fmt.Errorf("number %s: %w", n, err)
+	}
+	if f.Sign() == 0 {
+		return "0", nil
+	}
+
+	s := f.Text('f', -1)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s, nil
+}
@@ -0,0 +1,127 @@
+package convert
+
+import (
+	"sort"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// lexComments collects the comment tokens of the file being converted.
+func (c *Converter) lexComments() {
+	c.comments = nil
+	if c.opts.CommentKey == "" {
+		return
+	}
+
+	tokens, _ := hclsyntax.LexConfig(c.bytes, "", hcl.Pos{Line: 1, Column: 1})
+	for _, token := range tokens {
+		if token.Type == hclsyntax.TokenComment {
+			c.comments = append(c.comments, token)
+		}
+	}
+}
+
+// attachComments adds the comments of the attributes of body to out, under
+// Options.CommentKey. The key maps attribute names to their comment text.
+func (c *Converter) attachComments(body *hclsyntax.Body, out jsonObj) {
+	if c.opts.CommentKey == "" {
+		return
+	}
+	for name, attr := range body.Attributes {
+		if c.isInlinedLocal(attr) {
+			continue
+		}
+		if text := c.commentText(attr.SrcRange.Start, attr.SrcRange.End); text != "" {
+			c.commentObject(out)[name] = text
+		}
+	}
+}
+
+// attachBlockComments adds the comments of block to its converted body,
+// under Options.CommentKey and the empty name.
+func (c *Converter) attachBlockComments(block *hclsyntax.Block, body jsonObj) {
+	if c.opts.CommentKey == "" {
+		return
+	}
+	if text := c.commentText(block.TypeRange.Start, block.OpenBraceRange.End); text != "" {
+		c.commentObject(body)[""] = text
+	}
+}
+
+func (c *Converter) commentObject(out jsonObj) jsonObj {
+	comments, ok := out[c.opts.CommentKey].(jsonObj)
+	if !ok {
+		comments = make(jsonObj)
+		out[c.opts.CommentKey] = comments
+	}
+	return comments
+}
+
+// commentText returns the comments on the lines right above start, with no
+// blank line in between, and the comment following end on its line.
+func (c *Converter) commentText(start, end hcl.Pos) string {
+	var lines []string
+
+	// the first comment at or after start
+	next := sort.Search(len(c.comments), func(i int) bool {
+		return c.comments[i].Range.Start.Byte >= start.Byte
+	})
+
+	line := start.Line - 1
+	var leading []string
+	for i := next - 1; i >= 0; i-- {
+		comment := c.comments[i]
+		if commentEndLine(comment) != line || !c.ownLine(comment) {
+			break
+		}
+		leading = append([]string{commentBody(comment)}, leading...)
+		c.countKeptComment(i)
+		line = comment.Range.Start.Line - 1
+	}
+	lines = append(lines, leading...)
+
+	trailing := sort.Search(len(c.comments), func(i int) bool {
+		return c.comments[i].Range.Start.Byte >= end.Byte
+	})
+	if trailing < len(c.comments) && c.comments[trailing].Range.Start.Line == end.Line {
+		lines = append(lines, commentBody(c.comments[trailing]))
+		c.countKeptComment(trailing)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ownLine reports whether only whitespace precedes comment on its line.
+func (c *Converter) ownLine(comment hclsyntax.Token) bool {
+	for i := comment.Range.Start.Byte - 1; i >= 0 && c.bytes[i] != '\n'; i-- {
+		if c.bytes[i] != ' ' && c.bytes[i] != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// commentEndLine is the last line with comment text; line comments include
+// their newline, so their range ends on the next line.
+func commentEndLine(comment hclsyntax.Token) int {
+	if strings.HasPrefix(string(comment.Bytes), "/*") {
+		return comment.Range.End.Line
+	}
+	return comment.Range.Start.Line
+}
+
+// commentBody strips the comment markers of comment.
+func commentBody(comment hclsyntax.Token) string {
+	text := string(comment.Bytes)
+	switch {
+	case strings.HasPrefix(text, "/*"):
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+	case strings.HasPrefix(text, "//"):
+		text = strings.TrimPrefix(text, "//")
+	default:
+		text = strings.TrimPrefix(text, "#")
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,97 @@
+package convert
+
+import (
+	"io/fs"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Console evaluates a single expression, much like `terraform console`, and
+// returns its value as JSON. If fsys is not nil, the variable defaults and
+// locals of the module in dir are available as var.* and local.*.
+// Variables without a default and values that depend on resources are
+// unknown, and an expression that depends on them fails.
+func Console(expr string, fsys fs.FS, dir string) ([]byte, error) {
+	parsed, diags := hclsyntax.ParseExpression([]byte(expr), "<console>", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse expression", diags)
+	}
+
+	ctx := &hcl.EvalContext{
+		Functions: map[string]function.Function{},
+		Variables: map[string]cty.Value{},
+	}
+	for name, fn := range evalContext.Functions {
+		ctx.Functions[name] = fn
+	}
+	if fsys != nil {
+		files, err := parseModule(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+		moduleVariables(ctx, files)
+	}
+
+	value, diags := parsed.Value(ctx)
+	if diags.HasErrors() {
+		r := parsed.Range()
+		return nil, newError(CodeInvalidValue, &r, "evaluate expression", diags)
+	}
+	if !value.IsWhollyKnown() {
+		r := parsed.Range()
+		return nil, newError(CodeInvalidValue, &r, "value is not known until apply", nil)
+	}
+
+	return encodeJSON(ctyjson.SimpleJSONValue{Value: value})
+}
+
+// moduleVariables adds var and local to ctx from the variable and locals
+// blocks of files. Locals are resolved in as many passes as it takes for
+// their references to each other to settle; the rest stay unknown.
+func moduleVariables(ctx *hcl.EvalContext, files []*hcl.File) {
+	vars := map[string]cty.Value{}
+	pending := map[string]hcl.Expression{}
+	for _, file := range files {
+		for _, block := range moduleBlocks(file) {
+			switch block.Type {
+			case "variable":
+				vars[block.Labels[0]] = cty.DynamicVal
+				attrs, _, _ := block.Body.PartialContent(variableSchema)
+				if attr, exists := attrs.Attributes["default"]; exists {
+					if value, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+						vars[block.Labels[0]] = value
+					}
+				}
+			case "locals":
+				attrs, _ := block.Body.JustAttributes()
+				for name, attr := range attrs {
+					pending[name] = attr.Expr
+				}
+			}
+		}
+	}
+	ctx.Variables["var"] = cty.ObjectVal(vars)
+
+	locals := map[string]cty.Value{}
+	for progress := true; progress && len(pending) > 0; {
+		progress = false
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+		for name, expr := range pending {
+			value, diags := expr.Value(ctx)
+			if diags.HasErrors() {
+				continue
+			}
+			locals[name] = value
+			delete(pending, name)
+			progress = true
+		}
+	}
+	for name := range pending {
+		locals[name] = cty.DynamicVal
+	}
+	ctx.Variables["local"] = cty.ObjectVal(locals)
+}
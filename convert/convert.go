@@ -2,16 +2,19 @@ package convert
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	ctyconvert "github.com/zclconf/go-cty/cty/convert"
-	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
 const (
@@ -19,15 +22,17 @@ const (
 	LogColor2    = "\033[1;34m%s\033[0m"
 	LogColor3    = "\033[1;36m%s\033[0m"
 	WarningColor = "\033[1;33m[Warning]\033[0m"
-	ErrorColor   = "\033[1;31m[Error]\033a[0m"
+	ErrorColor   = "\033[1;31m[Error]\033[0m"
 )
 
-// Bytes takes the contents of an HCL file, as bytes, and converts
-// them into a JSON representation of the HCL file.
+// HclToJson takes the contents of an HCL file, as bytes, and converts
+// them into a JSON representation of the HCL file. On a syntax error the
+// returned *Error wraps the parse hcl.Diagnostics; use ConvertWithOptions
+// with zero Options to get diagnostics, warnings included, directly.
 func HclToJson(bytes []byte, filename string) ([]byte, error) {
 	file, diags := hclsyntax.ParseConfig(bytes, filename, hcl.Pos{Line: 1, Column: 1})
 	if diags.HasErrors() {
-		return nil, fmt.Errorf("parse config: %v", diags.Errs())
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse config", diags)
 	}
 
 	hclBytes, err := File(file)
@@ -38,6 +43,41 @@ func HclToJson(bytes []byte, filename string) ([]byte, error) {
 	return hclBytes, nil
 }
 
+// HclToJsonContext is like HclToJson, but stops between blocks and
+// attributes and returns ctx's error once ctx is done.
+func HclToJsonContext(ctx context.Context, bytes []byte, filename string) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(bytes, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse config", diags)
+	}
+
+	convertedFile, err := NewConverter(Options{}).WithContext(ctx).ConvertFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("convert file: %w", err)
+	}
+
+	return encodeJSON(convertedFile)
+}
+
+// ConvertString is HclToJson for string input and output, for callers such
+// as JavaScript bindings that deal in strings. Like HclToJson it neither
+// prints nor colors anything.
+func ConvertString(src string) (string, error) {
+	out, err := HclToJson([]byte(src), "<input>")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// ConvertWithOptions is like HclToJson, but the conversion is controlled by
+// opts and problems are reported as diagnostics. In tolerant mode the
+// returned JSON may be non-nil alongside error diagnostics.
+func ConvertWithOptions(bytes []byte, filename string, opts Options) ([]byte, hcl.Diagnostics) {
+	artifacts := NewConverter(opts).convertAll(bytes, filename)
+	return artifacts.JSON, artifacts.Diagnostics
+}
+
 // File takes an HCL file and converts it to its JSON representation.
 func File(file *hcl.File) ([]byte, error) {
 	convertedFile, err := ConvertFile(file)
@@ -45,34 +85,291 @@ func File(file *hcl.File) ([]byte, error) {
 		return nil, fmt.Errorf("convert file: %w", err)
 	}
 
-	// MEMO : json marshall할 때 encoder의 옵션 escapehtml을 false로 설정.
+	return encodeJSON(convertedFile)
+}
+
+// jsonEncoder writes converted documents to w. Builds with the jsontext tag
+// replace it with the encoding/json/jsontext backend.
+var jsonEncoder = encodeJSONStd
+
+func encodeJSON(convertedFile interface{}) ([]byte, error) {
 	buffer := &bytes.Buffer{}
-	encoder := json.NewEncoder(buffer)
+	if err := jsonEncoder(buffer, convertedFile); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func encodeJSONStd(w io.Writer, convertedFile interface{}) error {
+	// MEMO : json marshall할 때 encoder의 옵션 escapehtml을 false로 설정.
+	encoder := json.NewEncoder(w)
 	encoder.SetEscapeHTML(false)
 	encodeErr := encoder.Encode(convertedFile)
 	if encodeErr != nil {
-		return nil, fmt.Errorf("marshal json : %w", err)
+		return newError(CodeEncodeFailed, nil, "marshal json", encodeErr)
 	}
-	jsonBytes := buffer.Bytes()
 
-	return jsonBytes, nil
+	return nil
 }
 
 type jsonObj = map[string]interface{}
 
-type converter struct {
+// Converter converts HCL native syntax to its JSON representation. The
+// strategy used for a kind of expression can be replaced with Handle, so
+// embedders can tweak one behavior without copying the whole conversion.
+//
+// A Converter must not be used by multiple goroutines at the same time.
+type Converter struct {
 	bytes []byte
+	opts  Options
+
+	// parse diagnostics of the file, consulted in tolerant mode
+	parseDiags hcl.Diagnostics
+
+	strategies map[reflect.Type]ExpressionFunc
+
+	// path of the value being converted
+	path Path
+	// content left out of the output so far
+	skipped []SkippedItem
+	// raw attribute sources keyed by JSON pointer
+	sources map[string]string
+	// JSON pointers of attributes injected from Options.Defaults
+	injected map[string]bool
+	// source ranges of blocks and attributes keyed by JSON pointer, if
+	// Options.CaptureRanges is set
+	ranges map[string]hcl.Range
+	// key order of the objects keyed by JSON pointer, if
+	// Options.PreserveOrder is set
+	keyOrder map[string][]string
+	// JSON pointers of the objects holding the blocks under a label
+	labelled map[string]bool
+	// comment tokens of the file, if Options.CommentKey is set
+	comments []hclsyntax.Token
+	// warnings produced during conversion
+	warnings hcl.Diagnostics
+	// errors collected during conversion, if Options.CollectErrors is set
+	errors hcl.Diagnostics
+	// dialect rule of the body being converted
+	rule *BodyRule
+	// types of the blocks enclosing the body being converted
+	blockTypes []string
+	// range of the block or expression last entered, reported by
+	// SafeConvert on a panic
+	converting hcl.Range
+	// nesting of the block or expression being converted, if
+	// Options.MaxDepth is set
+	depth int
+	// use of the keys of the body being converted, if Options.Strict is set
+	strictKeys map[string]strictKind
+	// names of the local values of the file, if Options.Strict is set
+	strictLocals map[string]bool
+	// whether the body being converted is that of a sensitive variable or
+	// output, if Options.RedactSensitive is set
+	sensitive bool
+	// counts of the conversion, if Options.Report is set
+	report *Report
+	// indexes of the comments attached under Options.CommentKey, if
+	// Options.Report is set
+	keptComments map[int]bool
+	// names of the locals resolved by resolveLocals, if
+	// Options.InlineLocals is set
+	inlinedLocals map[string]bool
+	// checked for cancellation between blocks and attributes and by
+	// evaluation, if set
+	ctx context.Context
+	// elements expanded by evaluation in the file, charged against
+	// Options.MaxExpansion
+	expanded int
+	// context used to simplify expressions
+	evalCtx *hcl.EvalContext
+}
+
+// ExpressionFunc converts a single expression. It receives the Converter so
+// it can convert nested expressions or fall back to DefaultExpression.
+type ExpressionFunc func(c *Converter, expr hclsyntax.Expression) (interface{}, error)
+
+// NewConverter returns a Converter using the given options.
+func NewConverter(opts Options) *Converter {
+	strategies := make(map[reflect.Type]ExpressionFunc, len(opts.ExpressionHandlers))
+	for kind, fn := range opts.ExpressionHandlers {
+		strategies[kind] = fn
+	}
+	c := &Converter{
+		opts:       opts,
+		strategies: strategies,
+	}
+	c.evalCtx = c.simplifyContext()
+	return c
+}
+
+// WithContext makes c check ctx between blocks and attributes and while
+// evaluating expressions, and stop converting with ctx's error once it is
+// done. It returns c.
+func (c *Converter) WithContext(ctx context.Context) *Converter {
+	c.ctx = ctx
+	return c
+}
+
+// Handle replaces the strategy for expressions of the same kind as kind,
+// e.g. Handle((*hclsyntax.ScopeTraversalExpr)(nil), fn). Strategies apply to
+// expressions in value position; parts of string templates are always
+// converted by the default strategy.
+func (c *Converter) Handle(kind hclsyntax.Expression, fn ExpressionFunc) {
+	c.strategies[reflect.TypeOf(kind)] = fn
+}
+
+// Source returns the source text of the given range of the file being
+// converted.
+func (c *Converter) Source(r hcl.Range) string {
+	return c.rangeSource(r)
 }
 
 func ConvertFile(file *hcl.File) (jsonObj, error) {
-	body, ok := file.Body.(*hclsyntax.Body)
-	if !ok {
-		return nil, fmt.Errorf("convert file body to body type")
+	return NewConverter(Options{}).ConvertFile(file)
+}
+
+// ConvertFile converts the body of file. Bodies in HCL JSON syntax are
+// passed through, but for redaction and Simplify.
+func (c *Converter) ConvertFile(file *hcl.File) (map[string]interface{}, error) {
+	return c.convertFile(file, nil)
+}
+
+// ConvertBody converts body, which may have been parsed with a schema of
+// the caller's own. src must be the source of the file body belongs to.
+// Native syntax bodies are converted like the body of a file; any other
+// body, such as a JSON syntax one, is read with JustAttributes and its
+// attributes converted one by one, JSON values being passed through but
+// for Redact and Simplify.
+func ConvertBody(body hcl.Body, src []byte) (map[string]interface{}, error) {
+	return NewConverter(Options{}).ConvertBody(body, src)
+}
+
+// ConvertBody is like the package-level ConvertBody, with c's options.
+func (c *Converter) ConvertBody(body hcl.Body, src []byte) (map[string]interface{}, error) {
+	c.reset(src, nil)
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		c.lexComments()
+		out, err := c.convertBody(visibleBody(syntaxBody))
+		if err != nil {
+			return nil, fmt.Errorf("convert body: %w", err)
+		}
+		return out, nil
 	}
 
-	c := converter{
-		bytes: file.Bytes,
+	if option := unsupportedJSONOption(c.opts); option != "" {
+		return nil, newError(CodeUnsupportedBody, nil, option+" cannot be applied to JSON syntax input", nil)
+	}
+	attributes, diags := body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, newError(CodeUnsupportedBody, diags[0].Subject, "read attributes", diags)
 	}
+	out := make(jsonObj)
+	for name, attr := range attributes {
+		value, err := c.convertBodyExpression(attr.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("convert attribute %s: %w", name, err)
+		}
+		out[name] = value
+		if err := c.convertJSONAttribute(out, name); err != nil {
+			return nil, fmt.Errorf("convert attribute %s: %w", name, err)
+		}
+	}
+	return out, nil
+}
+
+// visibleBody returns a copy of body without the attributes and blocks a
+// schema already consumed, as hidden by PartialContent, which convertBody
+// would otherwise convert.
+func visibleBody(body *hclsyntax.Body) *hclsyntax.Body {
+	schema := &hcl.BodySchema{}
+	for name := range body.Attributes {
+		schema.Attributes = append(schema.Attributes, hcl.AttributeSchema{Name: name})
+	}
+	content, _, _ := body.PartialContent(schema)
+
+	visible := *body
+	visible.Attributes = make(hclsyntax.Attributes)
+	for name := range content.Attributes {
+		visible.Attributes[name] = body.Attributes[name]
+	}
+
+	// a schema takes a single label count per block type
+	shown := make(map[hcl.Body]bool)
+	seen := make(map[string]bool)
+	for _, block := range body.Blocks {
+		key := fmt.Sprintf("%s/%d", block.Type, len(block.Labels))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		schema := &hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: block.Type, LabelNames: block.Labels}}}
+		content, _, _ := body.PartialContent(schema)
+		for _, b := range content.Blocks {
+			shown[b.Body] = true
+		}
+	}
+	visible.Blocks = nil
+	for _, block := range body.Blocks {
+		if shown[block.Body] {
+			visible.Blocks = append(visible.Blocks, block)
+		}
+	}
+	return &visible
+}
+
+// convertBodyExpression converts the expression of a body that is not in
+// native syntax. Expressions other than native ones are decoded from their
+// source as JSON.
+func (c *Converter) convertBodyExpression(expr hcl.Expression) (interface{}, error) {
+	if syntaxExpr, ok := expr.(hclsyntax.Expression); ok {
+		return c.ConvertExpression(syntaxExpr)
+	}
+
+	r := expr.Range()
+	if r.Start.Byte < 0 || r.End.Byte > len(c.bytes) || r.Start.Byte > r.End.Byte {
+		return nil, newError(CodeUnsupportedBody, &r, "expression is out of the given source", nil)
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(c.bytes[r.Start.Byte:r.End.Byte]))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, newError(CodeUnsupportedBody, &r, fmt.Sprintf("%T is neither a native nor a JSON expression", expr), err)
+	}
+	return value, nil
+}
+
+// ConvertExpression converts a single expression in native syntax, such as
+// the default of a variable, the way it would be converted as the value of
+// an attribute. Like the values of ConvertFile, the result is meant to be
+// encoded with encoding/json.
+func ConvertExpression(src []byte) (interface{}, error) {
+	expr, diags := hclsyntax.ParseExpression(src, "<expression>", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse expression", diags)
+	}
+	return ConvertSyntaxExpression(expr, src)
+}
+
+// ConvertSyntaxExpression converts an already parsed expression. src must
+// be the source expr was parsed from, since expressions that are not
+// literals are converted to their source text.
+func ConvertSyntaxExpression(expr hclsyntax.Expression, src []byte) (interface{}, error) {
+	c := NewConverter(Options{})
+	c.reset(src, nil)
+	return c.ConvertExpression(expr)
+}
+
+func (c *Converter) convertFile(file *hcl.File, parseDiags hcl.Diagnostics) (jsonObj, error) {
+	c.reset(file.Bytes, parseDiags)
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return c.convertJSONBody(file)
+	}
+	c.lexComments()
+	c.checkResourceBudget(body)
+	c.resolveLocals(body)
 
 	out, err := c.convertBody(body)
 	if err != nil {
@@ -82,41 +379,280 @@ func ConvertFile(file *hcl.File) (jsonObj, error) {
 	return out, nil
 }
 
-func (c *converter) convertBody(body *hclsyntax.Body) (jsonObj, error) {
-	out := make(jsonObj)
+// reset clears the state of the previous conversion before converting the
+// given source.
+func (c *Converter) reset(src []byte, parseDiags hcl.Diagnostics) {
+	c.bytes = src
+	c.parseDiags = parseDiags
+	c.path = nil
+	c.skipped = nil
+	c.sources = make(map[string]string)
+	c.injected = make(map[string]bool)
+	c.ranges = make(map[string]hcl.Range)
+	c.keyOrder = make(map[string][]string)
+	c.labelled = make(map[string]bool)
+	c.strictLocals = nil
+	if c.opts.Strict {
+		c.strictLocals = make(map[string]bool)
+	}
+	c.warnings = nil
+	c.errors = nil
+	c.rule = nil
+	c.blockTypes = nil
+	c.sensitive = false
+	c.depth = 0
+	c.expanded = 0
+	c.converting = hcl.Range{}
+	c.report, c.keptComments, c.inlinedLocals = nil, nil, nil
+	if c.opts.InlineLocals {
+		// drop the locals of the previous file
+		c.evalCtx = c.simplifyContext()
+		c.inlinedLocals = make(map[string]bool)
+	}
+	if c.opts.Report {
+		c.report = &Report{Blocks: make(map[string]int)}
+		c.keptComments = make(map[int]bool)
+	}
+	if c.opts.Dialect != nil {
+		c.rule = &c.opts.Dialect.BodyRule
+	}
+}
 
-	for _, block := range body.Blocks {
-		fmt.Printf(LogColor2, "Convert Block : ")
-		fmt.Println("Type => '"+block.Type+"', Labels =>", block.Labels)
-		if err := c.convertBlock(block, out); err != nil {
-			return nil, fmt.Errorf("Unable to convert block: %w", err)
-		}
+func (c *Converter) convertBody(body *hclsyntax.Body) (jsonObj, error) {
+	out := make(jsonObj, len(body.Attributes)+len(body.Blocks))
+	c.checkBody(body)
+	if c.opts.Strict {
+		parentKeys := c.strictKeys
+		c.strictKeys = make(map[string]strictKind)
+		defer func() { c.strictKeys = parentKeys }()
 	}
 
-	var err error
-	for key, value := range body.Attributes {
-		fmt.Printf(LogColor2, "Convert Expression : ")
-		fmt.Println(key)
-		out[key], err = c.convertExpression(value.Expr)
-		if err != nil {
-			return nil, fmt.Errorf("Unable to convert expression: %w", err)
+	attributes := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attributes = append(attributes, attr)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].SrcRange.Start.Byte < attributes[j].SrcRange.Start.Byte
+	})
+
+	// Convert in source order, so that paths, events and the key order of
+	// Options.PreserveOrder follow the declarations.
+	blocks := body.Blocks
+	for len(blocks) > 0 || len(attributes) > 0 {
+		if c.ctx != nil && c.ctx.Err() != nil {
+			return nil, c.ctx.Err()
+		}
+		if len(blocks) > 0 && (len(attributes) == 0 || blocks[0].TypeRange.Start.Byte < attributes[0].SrcRange.Start.Byte) {
+			block := blocks[0]
+			blocks = blocks[1:]
+			if !c.blockSelected(block) {
+				continue
+			}
+			if c.opts.Logger != nil {
+				c.logf("Convert Block : Type => '%s', Labels => %v", block.Type, block.Labels)
+			}
+			if err := c.convertBlock(block, out); err != nil && !c.collectError(err, block.DefRange()) {
+				return nil, fmt.Errorf("Unable to convert block: %w", err)
+			}
+			continue
+		}
+
+		value := attributes[0]
+		attributes = attributes[1:]
+		_, collides := out[value.Name]
+		if err := c.convertAttribute(value, out); err != nil {
+			if !c.collectError(err, value.SrcRange) {
+				return nil, fmt.Errorf("Unable to convert expression: %w", err)
+			}
+			if !collides {
+				delete(out, value.Name)
+			}
 		}
 	}
+	c.attachComments(body, out)
 
 	return out, nil
 }
 
-func (c *converter) rangeSource(r hcl.Range) string {
-	// for some reason the range doesn't include the ending paren, so
-	// check if the next character is an ending paren, and include it if it is.
-	end := r.End.Byte
-	if end < len(c.bytes) && c.bytes[end] == ')' {
-		end++
+// collectError records err as a diagnostic, about subject unless err has a
+// subject of its own, and reports whether conversion may go on without the
+// failed attribute or block, as it may with Options.CollectErrors or
+// Lenient unless the conversion was cancelled.
+func (c *Converter) collectError(err error, subject hcl.Range) bool {
+	if !(c.opts.CollectErrors || c.opts.Lenient) || (c.ctx != nil && c.ctx.Err() != nil) {
+		return false
 	}
-	return string(c.bytes[r.Start.Byte:end])
+	diag := errorDiagnostic(err)
+	if diag.Subject == nil {
+		diag.Subject = &subject
+	}
+	c.errors = append(c.errors, diag)
+	return true
 }
 
-func (c *converter) convertBlock(block *hclsyntax.Block, out jsonObj) error {
+func (c *Converter) convertAttribute(value *hclsyntax.Attribute, out jsonObj) error {
+	key := value.Name
+	if c.isInlinedLocal(value) {
+		return nil
+	}
+	if c.opts.Logger != nil {
+		c.logf("Convert Expression : %s", key)
+	}
+	var replacement interface{}
+	if c.opts.BeforeAttribute != nil {
+		var keep bool
+		replacement, keep = c.opts.BeforeAttribute(append(c.currentPath(), key), value)
+		if !keep {
+			return nil
+		}
+	}
+	c.countAttribute()
+	if err := c.checkStrictAttribute(value); err != nil {
+		return err
+	}
+	if _, exists := out[key]; exists {
+		return attributeCollision(value, c.currentPath())
+	}
+	c.recordKey(c.path, key)
+
+	leave := c.enterPath(key)
+	defer leave()
+	c.checkValueBudget(value)
+	redacted := c.redacted()
+	if c.opts.CaptureSource && !redacted {
+		c.sources[c.path.Pointer()] = c.rangeSource(value.Expr.Range())
+	}
+	if c.opts.CaptureRanges {
+		c.ranges[c.path.Pointer()] = value.SrcRange
+	}
+
+	var err error
+	switch {
+	case replacement != nil:
+		out[key] = replacement
+		return nil
+	case c.isTerraformReference(key):
+		out[key] = c.referenceValue(value.Expr)
+	default:
+		out[key], err = c.ConvertExpression(value.Expr)
+	}
+	if err == nil {
+		out[key] = c.recognizeUnits(value, out[key])
+	}
+	if err == nil && redacted {
+		out[key], err = c.redact(out[key])
+	} else if err == nil {
+		var nested bool
+		if out[key], nested, err = c.redactNested(out[key]); nested {
+			// the source holds the redacted values in the clear
+			delete(c.sources, c.path.Pointer())
+		}
+	}
+	if err == nil && c.opts.AfterAttribute != nil {
+		var keep bool
+		if out[key], keep = c.opts.AfterAttribute(c.currentPath(), out[key]); !keep {
+			delete(out, key)
+			delete(c.sources, c.path.Pointer())
+			delete(c.ranges, c.path.Pointer())
+		}
+	}
+	return err
+}
+
+func (c *Converter) rangeSource(r hcl.Range) string {
+	// MEMO : hcl 구버전에서는 괄호식의 range에 닫는 괄호가 빠져 있어 다음 글자가 ')'이면 붙여줬었음.
+	// MEMO : 지금은 ParenthesesExpr가 괄호까지 range에 포함하므로, 붙이면 오히려 괄호가 하나 더 생길 수 있어 range 그대로 자름.
+	return string(c.bytes[r.Start.Byte:r.End.Byte])
+}
+
+func (c *Converter) convertBlock(block *hclsyntax.Block, out jsonObj) error {
+	c.converting = block.DefRange()
+	address := append([]string{block.Type}, block.Labels...)
+	blockPath := append(c.currentPath(), address...)
+	var replacement interface{}
+	if c.opts.BeforeBlock != nil {
+		var keep bool
+		if replacement, keep = c.opts.BeforeBlock(blockPath, block); !keep {
+			return nil
+		}
+	}
+	if c.allLocalsInlined(block) {
+		return nil
+	}
+	c.countBlock(block)
+
+	rule := c.blockRule(block)
+	shape := c.blockShape(block, rule)
+	array := shape == BlockShapeArray
+
+	provisioner := c.isTerraformProvisioner(block)
+	if !provisioner {
+		if err := c.checkStrictBlock(block); err != nil {
+			return err
+		}
+	}
+	segments := address
+	if provisioner {
+		list, _ := out[block.Type].([]interface{})
+		segments = []string{block.Type, strconv.Itoa(len(list)), block.Labels[0]}
+	} else if index := blockIndex(out, address, array); index >= 0 && shape != BlockShapeObject {
+		segments = append(segments[:len(segments):len(segments)], strconv.Itoa(index))
+	}
+	leave := c.enterPath(segments...)
+	parentRule, parentTypes, parentSensitive := c.rule, c.blockTypes, c.sensitive
+	c.rule = nil
+	if rule != nil {
+		c.rule = &rule.BodyRule
+	}
+	c.sensitive = c.isSensitiveBlock(block)
+	c.blockTypes = append(parentTypes[:len(parentTypes):len(parentTypes)], block.Type)
+	var body jsonObj
+	leaveDepth, err := c.enterDepth(block.DefRange())
+	if err == nil {
+		if replacement == nil {
+			body, err = c.convertBody(block.Body)
+		}
+		leaveDepth()
+	}
+	c.rule, c.blockTypes, c.sensitive = parentRule, parentTypes, parentSensitive
+	if err == nil && replacement == nil {
+		c.injectDefaults(address, body)
+	}
+	leave()
+	if err != nil {
+		return fmt.Errorf("convert body: %w", err)
+	}
+
+	var value interface{} = body
+	switch {
+	case replacement != nil:
+		value = replacement
+	case len(body) == 0:
+		switch c.opts.EmptyBlockMode {
+		case EmptyBlockOmit:
+			return nil
+		case EmptyBlockNull:
+			value = nil
+		}
+	}
+	if value != nil && replacement == nil {
+		c.attachBlockComments(block, body)
+	}
+	if c.opts.AfterBlock != nil && replacement == nil {
+		var keep bool
+		if value, keep = c.opts.AfterBlock(blockPath, value); !keep {
+			return nil
+		}
+	}
+	if c.opts.CaptureRanges {
+		c.ranges[append(c.currentPath(), segments...).Pointer()] = block.Range()
+	}
+
+	at := c.currentPath()
+	if provisioner {
+		c.appendProvisioner(at, block, out, value)
+		return nil
+	}
 	key := block.Type
 	for _, label := range block.Labels {
 
@@ -133,120 +669,317 @@ func (c *converter) convertBlock(block *hclsyntax.Block, out jsonObj) error {
 		// When the label exists, move onto the next label reference.
 		// When a label does not exist, create the label in the output and set that as the next label reference
 		// in order to append (potential) labels to it.
+		// the object at key must hold labels as well, not be the body of
+		// a block without them or the value of an attribute
+		container := append(at[:len(at):len(at)], key).Pointer()
 		if _, exists := out[key]; exists {
 			var ok bool
 			out, ok = out[key].(jsonObj)
-			if !ok {
-				return fmt.Errorf("Unable to convert Block to JSON: %v.%v", block.Type, strings.Join(block.Labels, "."))
+			if !ok || !c.labelled[container] {
+				return labelCollision(block)
 			}
 		} else {
+			c.recordKey(at, key)
 			out[key] = make(jsonObj)
 			out = out[key].(jsonObj)
+			c.labelled[container] = true
 		}
 
+		at = append(at, key)
 		key = label
 	}
 
-	value, err := c.convertBody(block.Body)
-	if err != nil {
-		return fmt.Errorf("convert body: %w", err)
-	}
-
 	// Multiple blocks can exist with the same name, at the same
 	// level in the JSON document (e.g. locals).
 	//
 	// For consistency, always wrap the value in a collection.
 	// When multiple values are at the same key
-	if current, exists := out[key]; exists {
+	if _, exists := out[key]; exists && c.labelled[append(at[:len(at):len(at)], key).Pointer()] {
+		return blockCollision(block, "a label of other blocks")
+	}
+	if current, exists := out[key]; exists && shape == BlockShapeObject {
+		if err := c.mergeBlock(block, out, key, value); err != nil {
+			return err
+		}
+	} else if exists {
 		// MEMO: Provider의 경우 중복된 키값으로 선언됨. 그럴 땐 terraform json syntax에 맞게 작성 되도록 처리해줌
-		if reflect.TypeOf(out[key]) == reflect.TypeOf(map[string]interface{}{}) {
-			var firstValue = out[key]
-			out[key] = []interface{}{firstValue}
-			current = out[key]
+		switch v := current.(type) {
+		case nil, jsonObj:
+			// the first block becomes the first element of an array
+			out[key] = []interface{}{v, value}
+			c.rebasePath(blockPath)
+			c.decide(DecisionArrayPromotion, blockPath, block.DefRange(), "block")
+		case []interface{}:
+			out[key] = append(v, value)
+		default:
+			return blockCollision(block, "an attribute")
 		}
-		out[key] = append(current.([]interface{}), value)
+	} else if array {
+		c.recordKey(at, key)
+		out[key] = []interface{}{value}
 	} else {
 		// out[key] = []interface{}{value}
+		c.recordKey(at, key)
 		out[key] = value
 	}
 
 	return nil
 }
 
-func (c *converter) convertExpression(expr hclsyntax.Expression) (interface{}, error) {
+// blockShape returns the shape of block from Options.BlockShapes, falling
+// back to TerraformMode and InlineLocals, AlwaysArrayBlocks and the dialect
+// rule of the block.
+func (c *Converter) blockShape(block *hclsyntax.Block, rule *BlockRule) BlockShape {
+	if shape, ok := c.opts.BlockShapes[block.Type]; ok && shape != BlockShapeAuto {
+		return shape
+	}
+	if (c.opts.TerraformMode || c.opts.InlineLocals) && block.Type == "locals" && len(c.blockTypes) == 0 {
+		return BlockShapeObject
+	}
+	if c.opts.AlwaysArrayBlocks || (rule != nil && rule.Repeated) {
+		return BlockShapeArray
+	}
+	return BlockShapeAuto
+}
+
+// mergeBlock merges the body of a repeated BlockShapeObject block into the
+// object already stored at out[key].
+// attributeCollision reports attr, at the path at, under the key of a block.
+func attributeCollision(attr *hclsyntax.Attribute, at Path) error {
+	return newError(CodeKeyCollision, &attr.NameRange, fmt.Sprintf("attribute %s has the name of a block", append(at, attr.Name)), nil)
+}
+
+// labelCollision reports a block whose labels would go under a key that
+// holds no labels, such as an attribute or a block without labels.
+func labelCollision(block *hclsyntax.Block) error {
+	r := block.DefRange()
+	return newError(CodeKeyCollision, &r, fmt.Sprintf("Unable to convert Block to JSON: %v.%v", block.Type, strings.Join(block.Labels, ".")), nil)
+}
+
+// blockCollision reports a block under a key that already holds what, e.g.
+// "an attribute".
+func blockCollision(block *hclsyntax.Block, what string) error {
+	r := block.DefRange()
+	address := append([]string{block.Type}, block.Labels...)
+	return newError(CodeKeyCollision, &r, fmt.Sprintf("block %s has the name of %s", Path(address), what), nil)
+}
+
+func (c *Converter) mergeBlock(block *hclsyntax.Block, out jsonObj, key string, value interface{}) error {
+	body, ok := value.(jsonObj)
+	if !ok {
+		return nil
+	}
+	current, ok := out[key].(jsonObj)
+	if !ok {
+		out[key] = body
+		return nil
+	}
+
+	for name, v := range body {
+		if comments, ok := v.(jsonObj); ok && name == c.opts.CommentKey {
+			for attr, text := range comments {
+				c.commentObject(current)[attr] = text
+			}
+			continue
+		}
+		if _, exists := current[name]; exists {
+			r := block.DefRange()
+			return newError(CodeKeyCollision, &r, fmt.Sprintf("Unable to merge Block %v: %v is already set", block.Type, name), nil)
+		}
+		current[name] = v
+	}
+	return nil
+}
+
+// ConvertExpression converts expr with the strategy registered for its kind,
+// or with DefaultExpression if there is none.
+func (c *Converter) ConvertExpression(expr hclsyntax.Expression) (interface{}, error) {
+	c.converting = expr.Range()
+	leave, err := c.enterDepth(expr.Range())
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	var value interface{}
+	if fn, ok := c.strategies[reflect.TypeOf(expr)]; ok {
+		value, err = fn(c, expr)
+	} else if c.opts.ExprMode == ExprModeAST && !isLiteralExpr(expr) {
+		value, err = c.exprAST(expr)
+	} else {
+		value, err = c.DefaultExpression(expr)
+	}
+	if err != nil && c.opts.Lenient {
+		return c.failedPlaceholder(expr, err), nil
+	}
+	return value, err
+}
+
+// DefaultExpression converts expr with the built-in strategy for its kind.
+// Nested expressions still go through ConvertExpression.
+func (c *Converter) DefaultExpression(expr hclsyntax.Expression) (interface{}, error) {
 
 	// assume it is hcl syntax (because, um, it is)
 	switch value := expr.(type) {
 	case *hclsyntax.LiteralValueExpr:
-		fmt.Printf(LogColor, "LiteralValueExpr: ")
-		fmt.Println(expr.Range())
-		return ctyjson.SimpleJSONValue{Value: value.Val}, nil
+		c.logExpr("LiteralValueExpr", expr)
+		// MEMO : 문법 오류가 난 expression은 parser가 unknown literal로 대체함.
+		if c.opts.Tolerant && !value.Val.IsKnown() {
+			return c.invalidPlaceholder(expr), nil
+		}
+		if number, ok := c.sourceNumber(value); ok {
+			return number, nil
+		}
+		return c.literal(value.Val, expr.Range())
 	case *hclsyntax.UnaryOpExpr:
-		fmt.Printf(LogColor, "UnaryOpExpr: ")
-		fmt.Println(expr.Range())
+		c.logExpr("UnaryOpExpr", expr)
 		return c.convertUnary(value)
 	case *hclsyntax.TemplateExpr:
-		fmt.Printf(LogColor, "TemplateExpr: ")
-		fmt.Println(expr.Range())
+		c.logExpr("TemplateExpr", expr)
+		if c.opts.Tolerant && c.invalidDiagnostic(expr) != nil {
+			return c.invalidPlaceholder(expr), nil
+		}
+		if c.opts.ValueEncoder != nil && value.IsStringLiteral() {
+			if val, diags := value.Value(nil); !diags.HasErrors() {
+				return c.literal(val, expr.Range())
+			}
+		}
 		return c.convertTemplate(value)
 	case *hclsyntax.TemplateWrapExpr:
-		fmt.Printf(LogColor, "TemplateWrapExpr: ")
-		fmt.Println(expr.Range())
-		return c.convertExpression(value.Wrapped)
+		c.logExpr("TemplateWrapExpr", expr)
+		return c.ConvertExpression(value.Wrapped)
 	case *hclsyntax.TupleConsExpr:
-		fmt.Printf(LogColor, "TupleConsExpr: ")
-		fmt.Println(expr.Range())
+		c.logExpr("TupleConsExpr", expr)
 		list := make([]interface{}, 0)
-		for _, ex := range value.Exprs {
-			elem, err := c.convertExpression(ex)
+		for i, ex := range value.Exprs {
+			leave := c.enterPath(strconv.Itoa(i))
+			elem, err := c.ConvertExpression(ex)
+			leave()
 			if err != nil {
 				return nil, err
 			}
 			list = append(list, elem)
 		}
 		return list, nil
+	case *hclsyntax.ScopeTraversalExpr:
+		c.logExpr("ScopeTraversalExpr", expr)
+		if s, ok := c.contextValue(value); ok {
+			return s, nil
+		}
+		if val, ok := c.simplified(expr); ok {
+			return c.literal(val, expr.Range())
+		}
+		if c.opts.StructuredReferences {
+			return c.referenceObject(value), nil
+		}
+		return c.wrapExpr(expr), nil
+	case *hclsyntax.ParenthesesExpr:
+		c.logExpr("ParenthesesExpr", expr)
+		// The range covers both parens, so the wrapped text keeps the
+		// grouping and with it the precedence of the inner expression.
+		if c.opts.Tolerant && c.invalidDiagnostic(expr) != nil {
+			return c.invalidPlaceholder(expr), nil
+		}
+		if val, ok := c.simplified(expr); ok {
+			return c.literal(val, expr.Range())
+		}
+		return c.wrapExpr(value), nil
+	case *hclsyntax.SplatExpr:
+		c.logExpr("SplatExpr", expr)
+		if c.opts.Tolerant && c.invalidDiagnostic(expr) != nil {
+			return c.invalidPlaceholder(expr), nil
+		}
+		return c.wrapExpr(value), nil
+	case *hclsyntax.AnonSymbolExpr:
+		return nil, c.anonSymbolError(value)
+	case *hclsyntax.ConditionalExpr:
+		c.logExpr("ConditionalExpr", expr)
+		if val, ok := c.simplified(expr); ok {
+			return c.literal(val, expr.Range())
+		}
+		if branch, ok := c.simplifiedBranch(value); ok {
+			return c.ConvertExpression(branch)
+		}
+		if c.opts.Tolerant && c.invalidDiagnostic(expr) != nil {
+			return c.invalidPlaceholder(expr), nil
+		}
+		if c.opts.StructuredReferences {
+			return c.conditionalObject(value)
+		}
+		return c.wrapExpr(expr), nil
+	case *hclsyntax.ForExpr:
+		c.logExpr("ForExpr", expr)
+		if c.opts.Tolerant && c.invalidDiagnostic(expr) != nil {
+			return c.invalidPlaceholder(expr), nil
+		}
+		if val, ok := c.simplified(expr); ok {
+			return c.literal(val, expr.Range())
+		}
+		if c.opts.StructuredReferences {
+			return c.forObject(value)
+		}
+		// The range covers the brackets, so the wrapped text is the whole
+		// tuple or object for expression and parses back as one.
+		return c.wrapExpr(value), nil
 	case *hclsyntax.ObjectConsExpr:
-		fmt.Printf(LogColor, "ObjectConsExpr: ")
-		fmt.Println(expr.Range())
+		c.logExpr("ObjectConsExpr", expr)
 		m := make(jsonObj)
 		for _, item := range value.Items {
 			key, err := c.convertKey(item.KeyExpr)
 			if err != nil {
 				return nil, err
 			}
-			m[key], err = c.convertExpression(item.ValueExpr)
+			if _, exists := m[key]; !exists {
+				c.recordKey(c.path, key)
+			}
+			leave := c.enterPath(key)
+			m[key], err = c.ConvertExpression(item.ValueExpr)
+			leave()
 			if err != nil {
 				return nil, err
 			}
 		}
 		return m, nil
 	default:
-		fmt.Printf(LogColor, "Default: ")
-		fmt.Println(expr.Range())
+		c.logExpr("Default", expr)
+		if c.opts.Tolerant && c.invalidDiagnostic(expr) != nil {
+			return c.invalidPlaceholder(expr), nil
+		}
+		if val, ok := c.simplified(expr); ok {
+			return c.literal(val, expr.Range())
+		}
 		return c.wrapExpr(expr), nil
 	}
 }
 
-func (c *converter) convertUnary(v *hclsyntax.UnaryOpExpr) (interface{}, error) {
+func (c *Converter) convertUnary(v *hclsyntax.UnaryOpExpr) (interface{}, error) {
+	if number, ok := c.sourceNumber(v); ok {
+		return number, nil
+	}
 	_, isLiteral := v.Val.(*hclsyntax.LiteralValueExpr)
 	if !isLiteral {
 		// If the expression after the operator isn't a literal, fall back to
 		// wrapping the expression with ${...}
+		if val, ok := c.simplified(v); ok {
+			return c.literal(val, v.Range())
+		}
 		return c.wrapExpr(v), nil
 	}
-	val, err := v.Value(nil)
-	if err != nil {
-		return nil, err
+	val, diags := v.Value(nil)
+	if diags.HasErrors() {
+		r := v.Range()
+		return nil, newError(CodeInvalidValue, &r, "", diags)
 	}
-	return ctyjson.SimpleJSONValue{Value: val}, nil
+	return c.literal(val, v.Range())
 }
 
-func (c *converter) convertTemplate(t *hclsyntax.TemplateExpr) (string, error) {
+func (c *Converter) convertTemplate(t *hclsyntax.TemplateExpr) (string, error) {
 	if t.IsStringLiteral() {
 		// safe because the value is just the string
-		v, err := t.Value(nil)
-		if err != nil {
-			return "", err
+		v, diags := t.Value(nil)
+		if diags.HasErrors() {
+			r := t.Range()
+			return "", newError(CodeInvalidValue, &r, "", diags)
 		}
 		return v.AsString(), nil
 	}
@@ -261,12 +994,13 @@ func (c *converter) convertTemplate(t *hclsyntax.TemplateExpr) (string, error) {
 	return builder.String(), nil
 }
 
-func (c *converter) convertStringPart(expr hclsyntax.Expression) (string, error) {
+func (c *Converter) convertStringPart(expr hclsyntax.Expression) (string, error) {
 	switch v := expr.(type) {
 	case *hclsyntax.LiteralValueExpr:
 		s, err := ctyconvert.Convert(v.Val, cty.String)
 		if err != nil {
-			return "", err
+			r := v.Range()
+			return "", newError(CodeInvalidValue, &r, "", err)
 		}
 		return s.AsString(), nil
 	case *hclsyntax.TemplateExpr:
@@ -274,17 +1008,42 @@ func (c *converter) convertStringPart(expr hclsyntax.Expression) (string, error)
 	case *hclsyntax.TemplateWrapExpr:
 		return c.convertStringPart(v.Wrapped)
 	case *hclsyntax.ConditionalExpr:
+		if branch, ok := c.simplifiedBranch(v); ok {
+			return c.convertStringPart(branch)
+		}
 		return c.convertTemplateConditional(v)
+	case *hclsyntax.ScopeTraversalExpr:
+		if s, ok := c.contextValue(v); ok {
+			return s, nil
+		}
+		if s, ok := c.simplifiedString(expr); ok {
+			return s, nil
+		}
+		return c.wrapExprVarInString(expr), nil
+	case *hclsyntax.SplatExpr:
+		// The splat range covers the source, the splat marker and the
+		// traversal after it, in both the [*] and the legacy .* form.
+		return c.wrapExprVarInString(v), nil
+	case *hclsyntax.AnonSymbolExpr:
+		return "", c.anonSymbolError(v)
 	case *hclsyntax.TemplateJoinExpr:
-		return c.convertTemplateFor(v.Tuple.(*hclsyntax.ForExpr))
+		forExpr, ok := v.Tuple.(*hclsyntax.ForExpr)
+		if !ok {
+			r := v.Range()
+			return "", newError(CodeUnsupportedExpression, &r, fmt.Sprintf("template join over %T", v.Tuple), nil)
+		}
+		return c.convertTemplateFor(forExpr)
 	default:
 		// treating as an embedded expression
 		// MEMO : 만약 string안 변수만 다르게 감싸줘야 한다면 이 부분 wrapExprVarInString로 수정하기
+		if s, ok := c.simplifiedString(expr); ok {
+			return s, nil
+		}
 		return c.wrapExprVarInString(expr), nil
 	}
 }
 
-func (c *converter) convertKey(keyExpr hclsyntax.Expression) (string, error) {
+func (c *Converter) convertKey(keyExpr hclsyntax.Expression) (string, error) {
 	// a key should never have dynamic input
 	if k, isKeyExpr := keyExpr.(*hclsyntax.ObjectConsKeyExpr); isKeyExpr {
 		keyExpr = k.Wrapped
@@ -295,17 +1054,20 @@ func (c *converter) convertKey(keyExpr hclsyntax.Expression) (string, error) {
 	return c.convertStringPart(keyExpr)
 }
 
-func (c *converter) convertTemplateConditional(expr *hclsyntax.ConditionalExpr) (string, error) {
+func (c *Converter) convertTemplateConditional(expr *hclsyntax.ConditionalExpr) (string, error) {
 	var builder strings.Builder
 	builder.WriteString("%{if ")
 	builder.WriteString(c.rangeSource(expr.Condition.Range()))
 	builder.WriteString("}")
 	trueResult, err := c.convertStringPart(expr.TrueResult)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 	builder.WriteString(trueResult)
 	falseResult, err := c.convertStringPart(expr.FalseResult)
+	if err != nil {
+		return "", err
+	}
 	if len(falseResult) > 0 {
 		builder.WriteString("%{else}")
 		builder.WriteString(falseResult)
@@ -315,7 +1077,7 @@ func (c *converter) convertTemplateConditional(expr *hclsyntax.ConditionalExpr)
 	return builder.String(), nil
 }
 
-func (c *converter) convertTemplateFor(expr *hclsyntax.ForExpr) (string, error) {
+func (c *Converter) convertTemplateFor(expr *hclsyntax.ForExpr) (string, error) {
 	var builder strings.Builder
 	builder.WriteString("%{for ")
 	if len(expr.KeyVar) > 0 {
@@ -336,12 +1098,31 @@ func (c *converter) convertTemplateFor(expr *hclsyntax.ForExpr) (string, error)
 	return builder.String(), nil
 }
 
-func (c *converter) wrapExpr(expr hclsyntax.Expression) string {
+// anonSymbolError reports an AnonSymbolExpr converted on its own. It only
+// has meaning inside the Each of its SplatExpr and its range is just the
+// splat marker, so wrapping it would produce "${[*]}".
+func (c *Converter) anonSymbolError(expr *hclsyntax.AnonSymbolExpr) error {
+	r := expr.Range()
+	return newError(CodeUnsupportedExpression, &r, "anonymous splat symbol outside of its splat expression", nil)
+}
+
+func (c *Converter) wrapExpr(expr hclsyntax.Expression) string {
+	c.countWrapped()
+	c.decideExpr(DecisionWrap, expr)
 	return "${" + c.rangeSource(expr.Range()) + "}"
 }
 
 // MEMO : string안에 있는 ${}변수에 대해선 hcl->json replaceAll에서 변환 안되게 하기 위해 다른 기호로 wrapping함.
 // MEMO : 근데 변수랑 함수들 ${}로 감싸져도 테라폼에서 동작하면 굳이 다르게 안넣어줘도 될듯? 일단 뺌 => 이렇게 생각했으나, 함수 감싼 ${}는 없애줘야 할듯해서 다시 이거 사용함.
-func (c *converter) wrapExprVarInString(expr hclsyntax.Expression) string {
-	return "@@@{" + c.rangeSource(expr.Range()) + "}@@@"
+func (c *Converter) wrapExprVarInString(expr hclsyntax.Expression) string {
+	if c.opts.NoTemplateMarkers || c.opts.TerraformMode {
+		return c.wrapExpr(expr)
+	}
+	c.countWrapped()
+	c.decideExpr(DecisionMarker, expr)
+	prefix, suffix := c.opts.TemplateMarkerPrefix, c.opts.TemplateMarkerSuffix
+	if prefix == "" && suffix == "" {
+		prefix, suffix = "@@@{", "}@@@"
+	}
+	return prefix + c.rangeSource(expr.Range()) + suffix
 }
@@ -3,7 +3,6 @@ package convert
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"reflect"
 	"strings"
 
@@ -13,39 +12,63 @@ import (
 	// jsonParser "github.com/hashicorp/hcl/json/parser"
 )
 
+// JsonToHcl converts JSON to HCL, printing the objects listed as blocks by
+// the type schema as blocks. It returns nil if input cannot be converted.
 func JsonToHcl(input []byte, typeSchemaStr string) []byte {
+	out, _ := jsonToHclWithConfig(input, typeSchemaStr, hclprinter.DefaultConfig)
+	return out
+}
+
+// JsonToHclOrdered is like JsonToHcl, but reorders the items of every block
+// body according to ordering, e.g. hclprinter.DefaultOrdering.
+func JsonToHclOrdered(input []byte, typeSchemaStr string, ordering hclprinter.Ordering) []byte {
+	cfg := hclprinter.DefaultConfig
+	cfg.Ordering = &ordering
+	out, _ := jsonToHclWithConfig(input, typeSchemaStr, cfg)
+	return out
+}
+
+func jsonToHclWithConfig(input []byte, typeSchemaStr string, cfg hclprinter.Config) ([]byte, error) {
 
 	var typeSchema map[string]interface{}
 	json.Unmarshal([]byte(typeSchemaStr), &typeSchema)
 
 	// MEMO: json 재구성 함수 호출
-	input = regenJson(input)
-
-	bytes, err := convertJsonToHcl(input, typeSchema)
+	input, err := regenJson(input)
 	if err != nil {
-		fmt.Errorf("hclTojson() error. %s", err)
+		return nil, err
 	}
-	return bytes
+
+	return convertJsonToHcl(input, typeSchema, cfg)
 }
 
-func convertJsonToHcl(input []byte, typeSchema map[string]interface{}) ([]byte, error) {
+func convertJsonToHcl(input []byte, typeSchema map[string]interface{}, cfg hclprinter.Config) ([]byte, error) {
 	ast, err := jsonParser.Parse(input)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse JSON: %s", err)
+		return nil, newError(CodeInvalidJSON, nil, "unable to parse JSON", err)
 	}
 	var buf bytes.Buffer
-	if err := hclprinter.Fprint(&buf, ast, typeSchema); err != nil {
-		return nil, fmt.Errorf("Unable to print HCL: %s", err)
+	if err := cfg.Fprint(&buf, ast, typeSchema); err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "Unable to print HCL", err)
 	}
 
 	return buf.Bytes(), nil
 }
 
+func isMap(value interface{}) bool {
+	return value != nil && reflect.TypeOf(value).Kind() == reflect.Map
+}
+
 // MEMO : json 내 프로퍼티가 부모 프로퍼티 경로를 포함하도록 재구성 (map / object 구분 위함)
-func regenJson(input []byte) []byte {
+func regenJson(input []byte) ([]byte, error) {
 
+	// MEMO : float64로 읽으면 큰 정수나 긴 소수의 정밀도가 손실되므로 json.Number로 읽음.
 	var data map[string]interface{}
-	json.Unmarshal(input, &data)
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, newError(CodeInvalidJSON, nil, "unable to parse JSON", err)
+	}
 
 	for key, value := range data {
 		if strings.Contains(key, "**##**") {
@@ -55,7 +78,7 @@ func regenJson(input []byte) []byte {
 		tmp := key
 		key = tmp + "**##**"
 
-		if reflect.TypeOf(value).Kind() == reflect.Map {
+		if isMap(value) {
 			data1 := value.(map[string]interface{})
 
 			for key, value := range data1 {
@@ -66,7 +89,7 @@ func regenJson(input []byte) []byte {
 				tmp1 := key
 				key = tmp + "**##**" + tmp1
 
-				if reflect.TypeOf(value).Kind() == reflect.Map {
+				if isMap(value) {
 					data2 := value.(map[string]interface{})
 
 					for key, value := range data2 {
@@ -77,7 +100,7 @@ func regenJson(input []byte) []byte {
 						tmp2 := key
 						key = tmp + "**##**" + tmp1 + "**##**" + key
 
-						if reflect.TypeOf(value).Kind() == reflect.Map {
+						if isMap(value) {
 							data3 := value.(map[string]interface{})
 
 							for key, value := range data3 {
@@ -87,7 +110,7 @@ func regenJson(input []byte) []byte {
 								tmp3 := key
 								key = tmp + "**##**" + tmp1 + "**##**" + tmp2 + "**##**" + key
 
-								if reflect.TypeOf(value).Kind() == reflect.Map {
+								if isMap(value) {
 									data4 := value.(map[string]interface{})
 									for key, value := range data4 {
 										if strings.Contains(key, "**##**") {
@@ -96,7 +119,7 @@ func regenJson(input []byte) []byte {
 										tmp4 := key
 										key = tmp + "**##**" + tmp1 + "**##**" + tmp2 + "**##**" + tmp3 + "**##**" + key
 
-										if reflect.TypeOf(value).Kind() == reflect.Map {
+										if isMap(value) {
 											data5 := value.(map[string]interface{})
 											for key, value := range data5 {
 												if strings.Contains(key, "**##**") {
@@ -105,7 +128,7 @@ func regenJson(input []byte) []byte {
 												tmp5 := key
 												key = tmp + "**##**" + tmp1 + "**##**" + tmp2 + "**##**" + tmp3 + "**##**" + tmp4 + "**##**" + key
 
-												if reflect.TypeOf(value).Kind() == reflect.Map {
+												if isMap(value) {
 													data6 := value.(map[string]interface{})
 													for key, value := range data6 {
 														if strings.Contains(key, "**##**") {
@@ -163,7 +186,9 @@ func regenJson(input []byte) []byte {
 		}
 		//delete(data, tmp)
 	}
-	fmt.Println(data)
-	output, _ := json.Marshal(data)
-	return output
+	output, err := json.Marshal(data)
+	if err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "marshal json", err)
+	}
+	return output, nil
 }
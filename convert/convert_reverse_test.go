@@ -0,0 +1,132 @@
+package convert
+
+import (
+	"testing"
+)
+
+func TestJsonToNativeHcl(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "locals",
+			input: `{"locals":{"a":"x-@@@{var.b}@@@","c":"${var.d}","e":">= 1.0"}}`,
+			want: `locals {
+  a = "x-${var.b}"
+  c = var.d
+  e = ">= 1.0"
+}
+`,
+		},
+		{
+			name:  "resource",
+			input: `{"resource":{"aws_security_group":{"web":{"name":"web","tags":{"Name":"web"},"ingress":[{"from_port":80},{"from_port":443}],"lifecycle":{"create_before_destroy":true}}}}}`,
+			want: `resource "aws_security_group" "web" {
+  name = "web"
+  tags = {
+    Name = "web"
+  }
+  ingress {
+    from_port = 80
+  }
+  ingress {
+    from_port = 443
+  }
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`,
+		},
+		{
+			name:  "terraform",
+			input: `{"terraform":{"required_version":">= 1.0","required_providers":{"aws":{"source":"hashicorp/aws"}},"backend":{"s3":{"bucket":"b"}}}}`,
+			want: `terraform {
+  required_version = ">= 1.0"
+  backend "s3" {
+    bucket = "b"
+  }
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+`,
+		},
+		{
+			name:  "repeated labels",
+			input: `{"provider":{"aws":[{"region":"eu"},{"alias":"us","region":"us"}]},"variable":{"a":null}}`,
+			want: `provider "aws" {
+  region = "eu"
+}
+
+provider "aws" {
+  alias  = "us"
+  region = "us"
+}
+
+variable "a" {
+}
+`,
+		},
+		{
+			name:  "unknown keys",
+			input: `{"a":{"b":[1,"${c}"]},"region":"eu"}`,
+			want: `a = {
+  b = [1, c]
+}
+region = "eu"
+`,
+		},
+		{
+			name:  "templates",
+			input: `{"locals":{"a":"q\"${lookup(m, \"k\")}\"\n","b":"%{if x}y%{endif}","c":"${x"}}`,
+			want: `locals {
+  a = "q\"${lookup(m, "k")}\"\n"
+  b = "%{if x}y%{endif}"
+  c = "$${x"
+}
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := JsonToNativeHcl([]byte(test.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJsonToNativeHclErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  Code
+	}{
+		{"invalid", `{"a":`, CodeInvalidJSON},
+		{"not an object", `[1]`, CodeInvalidJSON},
+		{"attribute name", `{"a b":1}`, CodeInvalidValue},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := JsonToNativeHcl([]byte(test.input))
+			if CodeOf(err) != test.code {
+				t.Errorf("got %v, want %s", err, test.code)
+			}
+		})
+	}
+}
+
+func TestRegenJsonError(t *testing.T) {
+	if out := JsonToHcl([]byte(`[1]`), ""); out != nil {
+		t.Errorf("got %s, want nil", out)
+	}
+}
@@ -0,0 +1,144 @@
+package convert
+
+import (
+	"testing"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestBlockCollisions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+		code Code
+	}{
+		{name: "block after attribute", src: "a = 1\na {}", code: CodeKeyCollision},
+		{name: "attribute after block", src: "a {}\na = 1", code: CodeKeyCollision},
+		{name: "labelled block after attribute", src: "a = 1\na \"x\" {}", code: CodeKeyCollision},
+		{name: "attribute after labelled block", src: "a \"x\" {}\na = 1", code: CodeKeyCollision},
+		{name: "labelled block after block", src: "a {}\na \"x\" {}", code: CodeKeyCollision},
+		{name: "block after labelled block", src: "a \"x\" {}\na {}", code: CodeKeyCollision},
+		{name: "array promotion", src: "a { x = 1 }\na { x = 2 }", want: `{"a":[{"x":1},{"x":2}]}`},
+		{name: "array append", src: "a { x = 1 }\na { x = 2 }\na { x = 3 }", want: `{"a":[{"x":1},{"x":2},{"x":3}]}`},
+		{name: "empty blocks", src: "a {}\na {}", want: `{"a":[{},{}]}`},
+		{
+			name: "nested label paths",
+			src:  "r \"x\" \"y\" { v = 1 }\nr \"x\" \"y\" { v = 2 }\nr \"x\" \"z\" {}",
+			want: `{"r":{"x":{"y":[{"v":1},{"v":2}],"z":{}}}}`,
+		},
+		{name: "nested blocks", src: "b {\n  c {}\n  c {}\n}", want: `{"b":{"c":[{},{}]}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, diags := ConvertWithOptions([]byte(test.src), "main.tf", Options{})
+			if test.code != "" {
+				if !diags.HasErrors() || diags[0].Summary != string(test.code)+": "+test.code.Summary() {
+					t.Fatalf("got %s, %v, want %s", got, diags, test.code)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+			if string(got) != test.want+"\n" {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBlockCollisionCollected(t *testing.T) {
+	got, diags := ConvertWithOptions([]byte("a { x = 1 }\na = 1"), "main.tf", Options{CollectErrors: true})
+	if len(diags) != 1 {
+		t.Fatalf("got %v, want one diagnostic", diags)
+	}
+	if want := `{"a":{"x":1}}` + "\n"; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRangeSource(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "multi-line", src: "a = (\n  b +\n  c\n) * d\n", want: `{"a":"${(\n  b +\n  c\n) * d}"}`},
+		{name: "nested", src: "a = [(b + c) * d, f((x)), -(y)]\n", want: `{"a":["${(b + c) * d}","${f((x))}","${-(y)}"]}`},
+		{name: "nested object", src: "a = { k = (b ? c : d).e }\n", want: `{"a":{"k":"${(b ? c : d).e}"}}`},
+		{name: "template", src: `a = "x${(b + c) * d}y"`, want: `{"a":"x@@@{(b + c) * d}@@@y"}`},
+		{name: "end of file", src: "a = (b + c)", want: `{"a":"${(b + c)}"}`},
+		{name: "end of file after comment", src: "a = !(b || c) && d\n# d", want: `{"a":"${!(b || c) && d}"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, diags := ConvertWithOptions([]byte(test.src), "main.tf", Options{})
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+			if string(got) != test.want+"\n" {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRangeSourceOfNestedExpression(t *testing.T) {
+	src := []byte("a = f(\n  (b + c) * d,\n  e)")
+	file, diags := hclsyntax.ParseConfig(src, "main.tf", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	call := file.Body.(*hclsyntax.Body).Attributes["a"].Expr.(*hclsyntax.FunctionCallExpr)
+	product := call.Args[0].(*hclsyntax.BinaryOpExpr)
+
+	c := NewConverter(Options{})
+	c.reset(src, nil)
+	for _, test := range []struct {
+		expr hclsyntax.Expression
+		want string
+	}{
+		{call, "f(\n  (b + c) * d,\n  e)"},
+		{product, "(b + c) * d"},
+		{product.LHS, "(b + c)"},
+		{call.Args[1], "e"},
+	} {
+		if got := c.Source(test.expr.Range()); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestTemplateConditional(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+		code Code
+	}{
+		{name: "if", src: `a = "%{if b}x%{endif}"`, want: `{"a":"%{if b}x%{endif}"}`},
+		{name: "if else", src: `a = "%{if b}x%{else}y%{endif}"`, want: `{"a":"%{if b}x%{else}y%{endif}"}`},
+		{name: "failing true branch", src: `a = "%{if b}${null}%{endif}"`, code: CodeInvalidValue},
+		{name: "failing false branch", src: `a = "%{if b}x%{else}${null}%{endif}"`, code: CodeInvalidValue},
+		{name: "failing nested branch", src: `a = "%{if b}%{if c}x%{else}${null}%{endif}%{endif}"`, code: CodeInvalidValue},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, diags := ConvertWithOptions([]byte(test.src), "main.tf", Options{})
+			if test.code != "" {
+				if !diags.HasErrors() || diags[0].Summary != string(test.code)+": "+test.code.Summary() {
+					t.Fatalf("got %s, %v, want %s", got, diags, test.code)
+				}
+				return
+			}
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+			if string(got) != test.want+"\n" {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
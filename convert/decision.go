@@ -0,0 +1,57 @@
+package convert
+
+import (
+	"math/rand"
+	"reflect"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// DecisionKind names a heuristic the converter applied where the JSON
+// output may lose information compared to the HCL source.
+type DecisionKind string
+
+const (
+	// DecisionWrap is an expression emitted as a "${...}" string.
+	DecisionWrap DecisionKind = "wrap"
+	// DecisionMarker is an expression inside a template emitted between
+	// @@@{ and }@@@ markers.
+	DecisionMarker DecisionKind = "marker"
+	// DecisionArrayPromotion is a block turned into an array because a
+	// second block with the same address followed it.
+	DecisionArrayPromotion DecisionKind = "array-promotion"
+)
+
+// Decision is one event reported to Options.OnDecision.
+type Decision struct {
+	Kind    DecisionKind `json:"kind"`
+	Address Path         `json:"address"`
+	Range   hcl.Range    `json:"range"`
+	// Construct is the HCL construct the decision was made for, e.g.
+	// "FunctionCallExpr" or "block".
+	Construct string `json:"construct"`
+}
+
+func (c *Converter) decide(kind DecisionKind, address Path, r hcl.Range, construct string) {
+	if c.opts.OnDecision == nil {
+		return
+	}
+	if rate := c.opts.DecisionSampleRate; rate > 0 && rate < 1 && rand.Float64() >= rate {
+		return
+	}
+
+	c.opts.OnDecision(Decision{
+		Kind:      kind,
+		Address:   address,
+		Range:     r,
+		Construct: construct,
+	})
+}
+
+func (c *Converter) decideExpr(kind DecisionKind, expr hclsyntax.Expression) {
+	if c.opts.OnDecision == nil {
+		return
+	}
+	c.decide(kind, c.currentPath(), expr.Range(), reflect.TypeOf(expr).Elem().Name())
+}
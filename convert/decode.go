@@ -0,0 +1,26 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Decode converts the input and unmarshals the resulting JSON into target,
+// which must be a pointer, honouring its json struct tags. Expressions that
+// are not literals decode as their "${...}" strings.
+func Decode(bytes []byte, filename string, target interface{}) error {
+	return decode(bytes, filename, Options{}, target)
+}
+
+func decode(bytes []byte, filename string, opts Options, target interface{}) error {
+	artifacts, err := ConvertAll(bytes, filename, opts)
+	if err != nil {
+		return fmt.Errorf("convert %s: %w", filename, err)
+	}
+
+	if err := json.Unmarshal(artifacts.JSON, target); err != nil {
+		return fmt.Errorf("decode %s into %T: %w", filename, target, err)
+	}
+
+	return nil
+}
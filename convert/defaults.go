@@ -0,0 +1,34 @@
+package convert
+
+import (
+	"sort"
+)
+
+// injectDefaults adds the attributes of Options.Defaults that body lacks.
+// A defaults key is matched like a Redact pattern, against the block type
+// and labels of the block, so "aws_instance.*" applies to every
+// resource "aws_instance" block.
+func (c *Converter) injectDefaults(address []string, body jsonObj) {
+	if len(c.opts.Defaults) == 0 {
+		return
+	}
+
+	patterns := make([]string, 0, len(c.opts.Defaults))
+	for pattern := range c.opts.Defaults {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if !matchPathSuffix(pattern, address) {
+			continue
+		}
+		for name, value := range c.opts.Defaults[pattern] {
+			if _, exists := body[name]; exists {
+				continue
+			}
+			body[name] = value
+			c.injected[append(c.currentPath(), name).Pointer()] = true
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package convert
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Delta is the result of ConvertChanged.
+type Delta struct {
+	// JSON is the document converted from the added and changed top-level
+	// blocks and attributes only.
+	JSON []byte
+	// Changed lists the addresses of added and changed items, Unchanged the
+	// ones found in both versions with the same structure, and Removed the
+	// ones that are only in the old version.
+	Changed   []Path
+	Unchanged []Path
+	Removed   []Path
+}
+
+// ConvertChanged compares the top-level blocks and attributes of two
+// versions of a file and converts only the ones that changed. Changes to
+// whitespace and comments do not count. Items are addressed by block type
+// and labels, plus the occurrence index when an address repeats.
+func ConvertChanged(oldBytes, newBytes []byte, filename string, opts Options) (*Delta, error) {
+	oldItems, err := topLevelItems(oldBytes, filename)
+	if err != nil {
+		return nil, err
+	}
+	newFile, diags := hclsyntax.ParseConfig(newBytes, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse new config", diags)
+	}
+	newItems := fileItems(newFile)
+	oldIndex := make(map[string]topLevelItem, len(oldItems))
+	for _, item := range oldItems {
+		oldIndex[item.address.Pointer()] = item
+	}
+
+	delta := &Delta{}
+	partial := &hclsyntax.Body{
+		Attributes: make(hclsyntax.Attributes),
+		SrcRange:   newFile.Body.(*hclsyntax.Body).SrcRange,
+		EndRange:   newFile.Body.(*hclsyntax.Body).EndRange,
+	}
+	seen := make(map[string]bool)
+	for _, item := range newItems {
+		key := item.address.Pointer()
+		seen[key] = true
+		if old, exists := oldIndex[key]; exists && old.fingerprint == item.fingerprint {
+			delta.Unchanged = append(delta.Unchanged, item.address)
+			continue
+		}
+
+		delta.Changed = append(delta.Changed, item.address)
+		if item.block != nil {
+			partial.Blocks = append(partial.Blocks, item.block)
+		} else {
+			partial.Attributes[item.attribute.Name] = item.attribute
+		}
+	}
+	for _, item := range oldItems {
+		if !seen[item.address.Pointer()] {
+			delta.Removed = append(delta.Removed, item.address)
+		}
+	}
+
+	out, err := NewConverter(opts).ConvertFile(&hcl.File{Body: partial, Bytes: newFile.Bytes})
+	if err != nil {
+		return nil, err
+	}
+	delta.JSON, err = encodeJSON(out)
+	if err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "encode partial document", err)
+	}
+
+	return delta, nil
+}
+
+type topLevelItem struct {
+	address     Path
+	fingerprint string
+	block       *hclsyntax.Block
+	attribute   *hclsyntax.Attribute
+}
+
+func topLevelItems(src []byte, filename string) ([]topLevelItem, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse old config", diags)
+	}
+	return fileItems(file), nil
+}
+
+// fileItems lists the top-level items of file in source order.
+func fileItems(file *hcl.File) []topLevelItem {
+	body := file.Body.(*hclsyntax.Body)
+
+	counts := make(map[string]int)
+	for _, block := range body.Blocks {
+		counts[strings.Join(append([]string{block.Type}, block.Labels...), "\x00")]++
+	}
+
+	var items []topLevelItem
+	occurrences := make(map[string]int)
+	for _, block := range body.Blocks {
+		address := append(Path{block.Type}, block.Labels...)
+		key := strings.Join(address, "\x00")
+		if counts[key] > 1 {
+			address = append(address, strconv.Itoa(occurrences[key]))
+			occurrences[key]++
+		}
+		items = append(items, topLevelItem{
+			address:     address,
+			fingerprint: fingerprint(block.Range().SliceBytes(file.Bytes)),
+			block:       block,
+		})
+	}
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+	})
+	for _, attr := range attrs {
+		items = append(items, topLevelItem{
+			address:     Path{attr.Name},
+			fingerprint: fingerprint(attr.SrcRange.SliceBytes(file.Bytes)),
+			attribute:   attr,
+		})
+	}
+
+	return items
+}
+
+// fingerprint reduces src to its tokens, so that two sources compare equal
+// when they only differ in whitespace and comments.
+func fingerprint(src []byte) string {
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl.Pos{Line: 1, Column: 1})
+
+	var b strings.Builder
+	newline := false
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenComment:
+			// Line comments swallow their newline.
+			newline = newline || strings.HasSuffix(string(token.Bytes), "\n")
+			continue
+		case hclsyntax.TokenNewline:
+			newline = true
+			continue
+		}
+		if newline {
+			b.WriteString("\n")
+			newline = false
+		}
+		b.WriteString(strconv.Itoa(int(token.Type)))
+		b.WriteString(strconv.Quote(string(token.Bytes)))
+	}
+	return b.String()
+}
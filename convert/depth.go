@@ -0,0 +1,51 @@
+package convert
+
+import (
+	"fmt"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// checkNesting reports the first bracket or template sequence of src nested
+// deeper than Options.MaxDepth. It runs on the tokens, before parsing, since
+// the parser recurses on nesting as well.
+func (c *Converter) checkNesting(src []byte, filename string) *hcl.Diagnostic {
+	if c.opts.MaxDepth <= 0 {
+		return nil
+	}
+
+	tokens, _ := hclsyntax.LexConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	depth := 0
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen,
+			hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+			if depth > c.opts.MaxDepth {
+				r := token.Range
+				return newError(CodeBudgetExceeded, &r, fmt.Sprintf("nesting is deeper than %d levels", c.opts.MaxDepth), nil).Diagnostic()
+			}
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen, hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+	}
+	return nil
+}
+
+// enterDepth counts one more level of nesting of blocks and expressions and
+// fails beyond Options.MaxDepth. The returned function leaves the level.
+func (c *Converter) enterDepth(r hcl.Range) (func(), error) {
+	if c.opts.MaxDepth <= 0 {
+		return func() {}, nil
+	}
+	c.depth++
+	leave := func() { c.depth-- }
+	if c.depth > c.opts.MaxDepth {
+		leave()
+		// copied here so that r only escapes on failure
+		subject := r
+		return nil, newError(CodeBudgetExceeded, &subject, fmt.Sprintf("nesting is deeper than %d levels", c.opts.MaxDepth), nil)
+	}
+	return leave, nil
+}
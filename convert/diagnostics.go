@@ -0,0 +1,138 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// DiagnosticJSON is the machine-readable form of an hcl.Diagnostic, for
+// frontends displaying diagnostics of their own.
+type DiagnosticJSON struct {
+	Severity string     `json:"severity"`
+	Summary  string     `json:"summary"`
+	Detail   string     `json:"detail,omitempty"`
+	Range    *RangeJSON `json:"range,omitempty"`
+	// Snippet is the source of the lines around the range, numbered, with
+	// a line of carets under the start of the range, as in:
+	//
+	//	1: a = [1,
+	//	       ^
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// RangeJSON is the machine-readable form of an hcl.Range.
+type RangeJSON struct {
+	Filename string  `json:"filename"`
+	Start    PosJSON `json:"start"`
+	End      PosJSON `json:"end"`
+}
+
+// PosJSON is the machine-readable form of an hcl.Pos.
+type PosJSON struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// DiagnosticsToJSON encodes diags as {"diagnostics": [...]}, each one a
+// DiagnosticJSON. files maps file names to the files the diagnostics refer
+// to, like for hcl.NewDiagnosticTextWriter; diagnostics about files missing
+// from it go without a snippet.
+func DiagnosticsToJSON(diags hcl.Diagnostics, files map[string]*hcl.File) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"diagnostics": diagnosticsJSON(diags, files)})
+}
+
+func diagnosticsJSON(diags hcl.Diagnostics, files map[string]*hcl.File) []DiagnosticJSON {
+	out := make([]DiagnosticJSON, 0, len(diags))
+	for _, diag := range diags {
+		severity := "error"
+		if diag.Severity == hcl.DiagWarning {
+			severity = "warning"
+		}
+		item := DiagnosticJSON{Severity: severity, Summary: diag.Summary, Detail: diag.Detail}
+		if diag.Subject != nil {
+			item.Range = &RangeJSON{
+				Filename: diag.Subject.Filename,
+				Start:    PosJSON{diag.Subject.Start.Line, diag.Subject.Start.Column, diag.Subject.Start.Byte},
+				End:      PosJSON{diag.Subject.End.Line, diag.Subject.End.Column, diag.Subject.End.Byte},
+			}
+			if file := files[diag.Subject.Filename]; file != nil {
+				item.Snippet = snippet(file.Bytes, diag.Subject, diag.Context)
+			}
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// maxSnippetLines bounds the lines of a snippet after the start of the
+// range, which may span a whole block.
+const maxSnippetLines = 2
+
+// snippet renders the lines of context, or of subject without one, with
+// carets under subject on its first line. It is empty if the ranges do not
+// fit src.
+func snippet(src []byte, subject, context *hcl.Range) string {
+	first, last := subject.Start.Line, subject.End.Line
+	if context != nil {
+		if context.Start.Line < first {
+			first = context.Start.Line
+		}
+		if context.End.Line > last {
+			last = context.End.Line
+		}
+	}
+	if last > subject.Start.Line+maxSnippetLines {
+		last = subject.Start.Line + maxSnippetLines
+	}
+	lines := bytes.Split(src, []byte("\n"))
+	if first < 1 || last > len(lines) || subject.Start.Byte > len(src) || subject.End.Byte > len(src) {
+		return ""
+	}
+
+	// the byte offset of the subject's start in its line
+	lineStart := bytes.LastIndexByte(src[:subject.Start.Byte], '\n') + 1
+	line := bytes.TrimSuffix(lines[subject.Start.Line-1], []byte("\r"))
+	offset := subject.Start.Byte - lineStart
+	if offset > len(line) {
+		return ""
+	}
+	end := len(line)
+	if subject.End.Line == subject.Start.Line && subject.End.Byte-lineStart < end {
+		end = subject.End.Byte - lineStart
+	}
+	if end < offset {
+		end = offset
+	}
+
+	// keep tabs in the padding, so that the carets line up with the source
+	var caret strings.Builder
+	for _, r := range string(line[:offset]) {
+		if r == '\t' {
+			caret.WriteByte('\t')
+		} else {
+			caret.WriteByte(' ')
+		}
+	}
+	carets := utf8.RuneCount(line[offset:end])
+	if carets == 0 {
+		carets = 1
+	}
+	caret.WriteString(strings.Repeat("^", carets))
+
+	width := len(fmt.Sprint(last))
+	var buf strings.Builder
+	for n := first; n <= last; n++ {
+		text := bytes.TrimSuffix(lines[n-1], []byte("\r"))
+		fmt.Fprintf(&buf, "%*d: %s\n", width, n, text)
+		if n == subject.Start.Line {
+			fmt.Fprintf(&buf, "%*s  %s\n", width, "", caret.String())
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,208 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Dialect describes an HCL language other than Terraform, such as an
+// organization-internal DSL. Its top level is a body rule, so a dialect
+// file looks like:
+//
+//	{
+//	  "name": "pipeline",
+//	  "blocks": {
+//	    "stage": {
+//	      "labels": 1,
+//	      "repeated": true,
+//	      "attributes": {"image": "string", "retries": "number"},
+//	      "required": ["image"]
+//	    }
+//	  }
+//	}
+type Dialect struct {
+	Name string `json:"name"`
+	BodyRule
+}
+
+// BodyRule describes the content allowed in a body. A nil Blocks or
+// Attributes map allows anything and an empty one allows nothing.
+type BodyRule struct {
+	Blocks map[string]*BlockRule `json:"blocks,omitempty"`
+	// Attributes maps attribute names to "string", "number", "bool",
+	// "list", "map" or "any". Only literal values are type checked.
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Required   []string          `json:"required,omitempty"`
+}
+
+// BlockRule describes one block type.
+type BlockRule struct {
+	Labels int `json:"labels"`
+	// Repeated blocks are always emitted as an array, even when only one
+	// block with the address exists.
+	Repeated bool `json:"repeated,omitempty"`
+	BodyRule
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = make(map[string]*Dialect)
+)
+
+// LoadDialect parses a dialect file, reporting attribute types other than
+// those listed for BodyRule.Attributes.
+func LoadDialect(src []byte) (*Dialect, error) {
+	var d Dialect
+	if err := json.Unmarshal(src, &d); err != nil {
+		return nil, newError(CodeInvalidJSON, nil, "parse dialect", err)
+	}
+	if d.Name == "" {
+		return nil, newError(CodeInvalidValue, nil, "dialect has no name", nil)
+	}
+	if err := d.BodyRule.validate(""); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// dialectTypes are the type names of BodyRule.Attributes.
+var dialectTypes = map[string]bool{"string": true, "number": true, "bool": true, "list": true, "map": true, "any": true}
+
+// validate reports attribute types of the rule, or of the rules of its
+// blocks, that are not dialect types. at is the path of the block types
+// leading to the rule.
+func (rule *BodyRule) validate(at string) error {
+	names := make([]string, 0, len(rule.Attributes))
+	for name := range rule.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if typeName := rule.Attributes[name]; !dialectTypes[typeName] {
+			return newError(CodeInvalidValue, nil, fmt.Sprintf("attribute %q%s has unknown type %q", name, at, typeName), nil)
+		}
+	}
+
+	types := make([]string, 0, len(rule.Blocks))
+	for typeName := range rule.Blocks {
+		types = append(types, typeName)
+	}
+	sort.Strings(types)
+	for _, typeName := range types {
+		block := rule.Blocks[typeName]
+		if block == nil {
+			return newError(CodeInvalidValue, nil, fmt.Sprintf("block %q%s has no rule", typeName, at), nil)
+		}
+		if err := block.validate(fmt.Sprintf(" of block %q%s", typeName, at)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterDialect makes d available to LookupDialect under its name,
+// replacing any dialect registered before with the same name.
+func RegisterDialect(d *Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[d.Name] = d
+}
+
+// LookupDialect returns the dialect registered under name.
+func LookupDialect(name string) (*Dialect, bool) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	d, ok := dialects[name]
+	return d, ok
+}
+
+// blockRule returns the rule for block in the current body, reporting
+// blocks the dialect does not allow.
+func (c *Converter) blockRule(block *hclsyntax.Block) *BlockRule {
+	if c.rule == nil || c.rule.Blocks == nil {
+		return nil
+	}
+
+	rule, ok := c.rule.Blocks[block.Type]
+	r := block.DefRange()
+	if !ok {
+		c.warnings = append(c.warnings, warningDiagnostic(CodeDialectViolation, &r,
+			fmt.Sprintf("Block type %q is not allowed here.", block.Type)))
+		return nil
+	}
+	if len(block.Labels) != rule.Labels {
+		c.warnings = append(c.warnings, warningDiagnostic(CodeDialectViolation, &r,
+			fmt.Sprintf("Block type %q takes %d labels, got %d.", block.Type, rule.Labels, len(block.Labels))))
+	}
+	return rule
+}
+
+// checkBody reports attributes of body the current rule does not allow,
+// literal values of the wrong type and missing required attributes.
+func (c *Converter) checkBody(body *hclsyntax.Body) {
+	if c.rule == nil {
+		return
+	}
+
+	if c.rule.Attributes != nil {
+		names := make([]string, 0, len(body.Attributes))
+		for name := range body.Attributes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			c.checkAttribute(body.Attributes[name])
+		}
+	}
+
+	for _, name := range c.rule.Required {
+		if _, exists := body.Attributes[name]; !exists {
+			r := body.SrcRange
+			c.warnings = append(c.warnings, warningDiagnostic(CodeDialectViolation, &r,
+				fmt.Sprintf("Missing required attribute %q.", name)))
+		}
+	}
+}
+
+func (c *Converter) checkAttribute(attr *hclsyntax.Attribute) {
+	r := attr.NameRange
+	typeName, ok := c.rule.Attributes[attr.Name]
+	if !ok {
+		c.warnings = append(c.warnings, warningDiagnostic(CodeDialectViolation, &r,
+			fmt.Sprintf("Attribute %q is not allowed here.", attr.Name)))
+		return
+	}
+
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() {
+		return
+	}
+	if !dialectTypeMatches(typeName, value.Type()) {
+		c.warnings = append(c.warnings, warningDiagnostic(CodeDialectViolation, &r,
+			fmt.Sprintf("Attribute %q must be %s, got %s.", attr.Name, typeName, value.Type().FriendlyName())))
+	}
+}
+
+func dialectTypeMatches(typeName string, ty cty.Type) bool {
+	switch typeName {
+	case "string":
+		return ty == cty.String
+	case "number":
+		return ty == cty.Number
+	case "bool":
+		return ty == cty.Bool
+	case "list":
+		return ty.IsTupleType() || ty.IsListType() || ty.IsSetType()
+	case "map":
+		return ty.IsObjectType() || ty.IsMapType()
+	case "any":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadDialect(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		code   Code
+		detail string
+	}{
+		{
+			name: "valid",
+			src:  `{"name":"pipeline","blocks":{"stage":{"labels":1,"attributes":{"image":"string","env":"map","extra":"any"}}}}`,
+		},
+		{
+			name:   "unknown top-level type",
+			src:    `{"name":"pipeline","attributes":{"image":"str"}}`,
+			code:   CodeInvalidValue,
+			detail: `attribute "image" has unknown type "str"`,
+		},
+		{
+			name:   "unknown nested type",
+			src:    `{"name":"pipeline","blocks":{"stage":{"blocks":{"step":{"attributes":{"retries":"int"}}}}}}`,
+			code:   CodeInvalidValue,
+			detail: `attribute "retries" of block "step" of block "stage" has unknown type "int"`,
+		},
+		{
+			name:   "null block rule",
+			src:    `{"name":"pipeline","blocks":{"stage":null}}`,
+			code:   CodeInvalidValue,
+			detail: `block "stage" has no rule`,
+		},
+		{
+			name: "no name",
+			src:  `{"attributes":{"image":"string"}}`,
+			code: CodeInvalidValue,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadDialect([]byte(test.src))
+			if CodeOf(err) != test.code {
+				t.Fatalf("got %v, want %s", err, test.code)
+			}
+			if test.detail != "" && !strings.Contains(err.Error(), test.detail) {
+				t.Errorf("got %v, want %s", err, test.detail)
+			}
+		})
+	}
+}
+
+func TestDialectTypeMatches(t *testing.T) {
+	d, err := LoadDialect([]byte(`{"name":"pipeline","attributes":{"image":"string","retries":"number","extra":"any"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an unknown type set up without LoadDialect matches nothing
+	d.Attributes["tag"] = "str"
+
+	_, diags := SafeConvert([]byte("image = 1\nretries = 2\nextra = true\ntag = \"v1\"\n"), "a.hcl", Options{Dialect: d})
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Detail)
+	}
+	want := []string{`Attribute "image" must be string, got number.`, `Attribute "tag" must be str, got string.`}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
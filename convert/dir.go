@@ -0,0 +1,266 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ConvertDir converts every .tf and .hcl file under dir, recursively, along
+// with their JSON syntax counterparts such as .tf.json, and returns the
+// documents keyed by their path relative to dir. Files and directories
+// whose name starts with a dot, such as .terraform.lock.hcl and .terraform
+// with its downloaded modules, are skipped.
+func ConvertDir(fsys fs.FS, dir string) (map[string]jsonObj, error) {
+	return ConvertDirContext(context.Background(), fsys, dir)
+}
+
+// ConvertDirContext is like ConvertDir, but stops between files and blocks
+// and returns ctx's error once ctx is done.
+func ConvertDirContext(ctx context.Context, fsys fs.FS, dir string) (map[string]jsonObj, error) {
+	documents := make(map[string]jsonObj)
+	err := fs.WalkDir(fsys, dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		hidden := name != dir && strings.HasPrefix(entry.Name(), ".")
+		if entry.IsDir() {
+			if hidden {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if hidden || !isConfigFile(name) {
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		artifacts, err := NewConverter(Options{}).WithContext(ctx).ConvertAll(src, name)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("convert %s: %w", name, err)
+		}
+
+		rel := strings.TrimPrefix(name, path.Clean(dir)+"/")
+		documents[rel] = artifacts.Document
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+func isConfigFile(name string) bool {
+	for _, suffix := range []string{".tf", ".hcl", ".tf.json", ".hcl.json"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueLabels is the number of labels of the top-level block types whose
+// blocks have an address that may only be declared once in a module; for
+// locals, the address is the name of each local value.
+var uniqueLabels = map[string]int{
+	"resource": 2,
+	"data":     2,
+	"module":   1,
+	"variable": 1,
+	"output":   1,
+	"locals":   1,
+}
+
+// MergeDocuments merges documents, such as the ones of ConvertDir, into a
+// single document the way Terraform combines the files of a module, in
+// file name order. Resources, data sources, modules, variables, outputs
+// and local values declared in more than one file are reported as
+// collisions. Providers of the same name are gathered into an array,
+// unless two of them have the same alias. Anything else is merged key by
+// key for objects and concatenated for arrays, and any other value defined
+// in more than one file is a collision.
+func MergeDocuments(documents map[string]jsonObj) (jsonObj, error) {
+	names := make([]string, 0, len(documents))
+	for name := range documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	merged := make(jsonObj)
+	for _, name := range names {
+		if err := mergeDocument(merged, documents[name]); err != nil {
+			return nil, fmt.Errorf("merge %s: %w", name, err)
+		}
+	}
+	return merged, nil
+}
+
+func mergeDocument(dst, src jsonObj) error {
+	for key, value := range src {
+		var err error
+		labels, unique := uniqueLabels[key]
+		switch {
+		case key == "locals":
+			err = mergeLocals(dst, value)
+		case key == "provider":
+			err = mergeProviders(dst, value)
+		case unique:
+			err = mergeAddresses(dst, key, value, labels, Path{key})
+		default:
+			err = mergeObject(dst, jsonObj{key: value}, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeAddresses merges the blocks under value, labels levels of objects
+// above their bodies, into dst[key], failing on a block already there.
+func mergeAddresses(dst jsonObj, key string, value interface{}, labels int, at Path) error {
+	current, exists := dst[key]
+	if !exists {
+		dst[key] = value
+		return nil
+	}
+	from, ok := value.(jsonObj)
+	to, isObject := current.(jsonObj)
+	if labels == 0 || !ok || !isObject {
+		return newError(CodeKeyCollision, nil, fmt.Sprintf("%s is declared more than once", at), nil)
+	}
+	for name, nested := range from {
+		if err := mergeAddresses(to, name, nested, labels-1, append(at[:len(at):len(at)], name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeLocals merges the locals blocks of value into dst["locals"], failing
+// on a local value already declared.
+func mergeLocals(dst jsonObj, value interface{}) error {
+	current, exists := dst["locals"]
+	if !exists {
+		dst["locals"] = value
+		return nil
+	}
+	declared := make(map[string]bool)
+	for _, block := range blockList(current) {
+		for name := range block {
+			declared[name] = true
+		}
+	}
+	for _, block := range blockList(value) {
+		for name := range block {
+			if declared[name] {
+				return newError(CodeKeyCollision, nil, fmt.Sprintf("%s is declared more than once", Path{"local", name}), nil)
+			}
+			declared[name] = true
+		}
+	}
+	to, isObject := current.(jsonObj)
+	from, ok := value.(jsonObj)
+	if !isObject || !ok {
+		dst["locals"] = combineBlocks(current, value)
+		return nil
+	}
+	for name, v := range from {
+		to[name] = v
+	}
+	return nil
+}
+
+// mergeProviders gathers the provider blocks of value into dst["provider"],
+// failing on a provider of the same name and alias as one already there.
+func mergeProviders(dst jsonObj, value interface{}) error {
+	from, ok := value.(jsonObj)
+	to, isObject := dst["provider"].(jsonObj)
+	if !isObject {
+		if _, exists := dst["provider"]; exists || !ok {
+			return newError(CodeKeyCollision, nil, `provider is defined more than once`, nil)
+		}
+		to = make(jsonObj, len(from))
+		dst["provider"] = to
+	}
+	for name, blocks := range from {
+		current, exists := to[name]
+		if !exists {
+			to[name] = blocks
+			continue
+		}
+		aliases := make(map[string]bool)
+		for _, block := range blockList(current) {
+			aliases[fmt.Sprint(block["alias"])] = true
+		}
+		for _, block := range blockList(blocks) {
+			if aliases[fmt.Sprint(block["alias"])] {
+				return newError(CodeKeyCollision, nil, fmt.Sprintf("%s is declared more than once", providerAddress(name, block)), nil)
+			}
+			aliases[fmt.Sprint(block["alias"])] = true
+		}
+		to[name] = combineBlocks(current, blocks)
+	}
+	return nil
+}
+
+func providerAddress(name string, block jsonObj) Path {
+	if alias, ok := block["alias"]; ok {
+		return Path{"provider", name, fmt.Sprint(alias)}
+	}
+	return Path{"provider", name}
+}
+
+// combineBlocks returns the blocks of current followed by the ones of
+// value, as an array.
+func combineBlocks(current, value interface{}) []interface{} {
+	var blocks []interface{}
+	for _, block := range blockList(current) {
+		blocks = append(blocks, block)
+	}
+	for _, block := range blockList(value) {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func mergeObject(dst, src jsonObj, at Path) error {
+	for key, value := range src {
+		current, exists := dst[key]
+		if !exists {
+			dst[key] = value
+			continue
+		}
+
+		keyPath := append(at[:len(at):len(at)], key)
+		switch v := value.(type) {
+		case jsonObj:
+			if c, ok := current.(jsonObj); ok {
+				if err := mergeObject(c, v, keyPath); err != nil {
+					return err
+				}
+				continue
+			}
+		case []interface{}:
+			if c, ok := current.([]interface{}); ok {
+				dst[key] = append(c[:len(c):len(c)], v...)
+				continue
+			}
+		}
+		return newError(CodeKeyCollision, nil, fmt.Sprintf("%s is defined more than once", keyPath), nil)
+	}
+	return nil
+}
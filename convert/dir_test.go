@@ -0,0 +1,147 @@
+package convert
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConvertDirSkipsDotDirectories(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.tf":                          {Data: []byte(`variable "a" {}`)},
+		"modules/vpc/main.tf":              {Data: []byte(`variable "b" {}`)},
+		".terraform/modules/x/main.tf":     {Data: []byte(`variable "c" {}`)},
+		".git/hooks/pre-commit.sample.hcl": {Data: []byte(`{`)},
+		".terraform.lock.hcl": {Data: []byte(`provider "registry.terraform.io/hashicorp/aws" {
+  version = "3.0.0"
+  hashes  = ["h1:abc"]
+}`)},
+		"modules/vpc/.terraform.lock.hcl": {Data: []byte(`provider "registry.terraform.io/hashicorp/aws" {
+  version = "3.0.0"
+}`)},
+	}
+	documents, err := ConvertDir(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 2 || documents["main.tf"] == nil || documents["modules/vpc/main.tf"] == nil {
+		t.Errorf("got documents of %v, want main.tf and modules/vpc/main.tf", keys(documents))
+	}
+	merged, err := MergeDocuments(documents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := merged["provider"]; ok {
+		t.Errorf("got providers %v of the lock files", merged["provider"])
+	}
+}
+
+func keys(documents map[string]jsonObj) []string {
+	names := make([]string, 0, len(documents))
+	for name := range documents {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestMergeDocuments(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+		code  Code
+	}{
+		{
+			name: "blocks of different files",
+			files: map[string]string{
+				"a.tf": `resource "aws_instance" "a" {}
+locals { x = 1 }`,
+				"b.tf": `resource "aws_instance" "b" {}
+locals { y = 2 }`,
+			},
+			want: `{"locals":{"x":1,"y":2},"resource":{"aws_instance":{"a":{},"b":{}}}}`,
+		},
+		{
+			name: "providers by alias",
+			files: map[string]string{
+				"a.tf": `provider "aws" { region = "a" }`,
+				"b.tf": `provider "aws" {
+  alias  = "b"
+  region = "b"
+}`,
+			},
+			want: `{"provider":{"aws":[{"region":"a"},{"alias":"b","region":"b"}]}}`,
+		},
+		{
+			name: "terraform blocks",
+			files: map[string]string{
+				"a.tf": `terraform { required_version = "1.0" }`,
+				"b.tf": `terraform {
+  backend "s3" {}
+}`,
+			},
+			want: `{"terraform":{"backend":{"s3":{}},"required_version":"1.0"}}`,
+		},
+		{
+			name: "duplicate resource",
+			files: map[string]string{
+				"a.tf": `resource "aws_instance" "a" { ami = "x" }`,
+				"b.tf": `resource "aws_instance" "a" { count = 1 }`,
+			},
+			code: CodeKeyCollision,
+		},
+		{
+			name: "duplicate variable",
+			files: map[string]string{
+				"a.tf": `variable "a" {}`,
+				"b.tf": `variable "a" {}`,
+			},
+			code: CodeKeyCollision,
+		},
+		{
+			name: "duplicate local",
+			files: map[string]string{
+				"a.tf": `locals { x = { a = 1 } }`,
+				"b.tf": `locals { x = { b = 2 } }`,
+			},
+			code: CodeKeyCollision,
+		},
+		{
+			name: "duplicate provider",
+			files: map[string]string{
+				"a.tf": `provider "aws" { region = "a" }`,
+				"b.tf": `provider "aws" { profile = "b" }`,
+			},
+			code: CodeKeyCollision,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			documents := make(map[string]jsonObj)
+			for name, src := range test.files {
+				artifacts, err := ConvertAll([]byte(src), name, Options{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				documents[name] = artifacts.Document
+			}
+			merged, err := MergeDocuments(documents)
+			if test.code != "" {
+				if CodeOf(err) != test.code {
+					t.Fatalf("got %v, want %s", err, test.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(merged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
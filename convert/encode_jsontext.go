@@ -0,0 +1,78 @@
+//go:build jsontext && goexperiment.jsonv2
+// +build jsontext,goexperiment.jsonv2
+
+package convert
+
+import (
+	"encoding/json"
+	"encoding/json/jsontext"
+	"io"
+	"sort"
+)
+
+func init() {
+	jsonEncoder = encodeJSONText
+}
+
+// encodeJSONText streams the document through a jsontext.Encoder instead of
+// reflecting over it with encoding/json. The output is byte-identical to
+// encodeJSONStd.
+func encodeJSONText(w io.Writer, convertedFile interface{}) error {
+	encoder := jsontext.NewEncoder(w,
+		jsontext.AllowInvalidUTF8(true),
+		jsontext.EscapeForHTML(false),
+		jsontext.EscapeForJS(true),
+	)
+	if err := writeJSONText(encoder, convertedFile); err != nil {
+		return newError(CodeEncodeFailed, nil, "marshal json", err)
+	}
+
+	return nil
+}
+
+func writeJSONText(encoder *jsontext.Encoder, value interface{}) error {
+	switch v := value.(type) {
+	case jsonObj:
+		if err := encoder.WriteToken(jsontext.BeginObject); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := encoder.WriteToken(jsontext.String(key)); err != nil {
+				return err
+			}
+			if err := writeJSONText(encoder, v[key]); err != nil {
+				return err
+			}
+		}
+		return encoder.WriteToken(jsontext.EndObject)
+	case []interface{}:
+		if err := encoder.WriteToken(jsontext.BeginArray); err != nil {
+			return err
+		}
+		for _, elem := range v {
+			if err := writeJSONText(encoder, elem); err != nil {
+				return err
+			}
+		}
+		return encoder.WriteToken(jsontext.EndArray)
+	case string:
+		return encoder.WriteToken(jsontext.String(v))
+	case json.Marshaler:
+		raw, err := v.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		return encoder.WriteValue(jsontext.Value(raw))
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return encoder.WriteValue(jsontext.Value(raw))
+	}
+}
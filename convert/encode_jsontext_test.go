@@ -0,0 +1,52 @@
+//go:build jsontext && goexperiment.jsonv2
+// +build jsontext,goexperiment.jsonv2
+
+package convert
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func benchmarkDocument(tb testing.TB) jsonObj {
+	artifacts, err := ConvertAll(benchmarkModule(1000), "main.tf", Options{})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return artifacts.Document
+}
+
+func TestEncodeJSONTextMatchesStd(t *testing.T) {
+	document := benchmarkDocument(t)
+	var std, text bytes.Buffer
+	if err := encodeJSONStd(&std, document); err != nil {
+		t.Fatal(err)
+	}
+	if err := encodeJSONText(&text, document); err != nil {
+		t.Fatal(err)
+	}
+	// encoding/json ends the document with a newline
+	if !bytes.Equal(bytes.TrimSuffix(std.Bytes(), []byte("\n")), bytes.TrimSuffix(text.Bytes(), []byte("\n"))) {
+		t.Error("the jsontext encoding differs from the encoding/json one")
+	}
+}
+
+func benchmarkEncoder(b *testing.B, encode func(io.Writer, interface{}) error) {
+	document := benchmarkDocument(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := encode(io.Discard, document); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeJSONStd(b *testing.B) {
+	benchmarkEncoder(b, encodeJSONStd)
+}
+
+func BenchmarkEncodeJSONText(b *testing.B) {
+	benchmarkEncoder(b, encodeJSONText)
+}
@@ -0,0 +1,154 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ValueEncoder overrides how cty values are rendered as JSON, e.g. to emit
+// sets as sorted arrays or tag tuples with their type. EncodeValue returns
+// false to leave val to the default encoding, which then asks the encoder
+// again for every element of a collection.
+type ValueEncoder interface {
+	EncodeValue(val cty.Value) (raw json.RawMessage, ok bool, err error)
+}
+
+// ValueEncoderFunc adapts a function to a ValueEncoder.
+type ValueEncoderFunc func(val cty.Value) (json.RawMessage, bool, error)
+
+// EncodeValue calls f(val).
+func (f ValueEncoderFunc) EncodeValue(val cty.Value) (json.RawMessage, bool, error) {
+	return f(val)
+}
+
+// sourceNumber returns the number literal expr as written in the source, if
+// Options.PreserveNumbers is set and the source is a valid JSON number. A
+// negative number is the negation of a literal.
+func (c *Converter) sourceNumber(expr hclsyntax.Expression) (json.Number, bool) {
+	if !c.opts.PreserveNumbers {
+		return "", false
+	}
+
+	sign := ""
+	if unary, ok := expr.(*hclsyntax.UnaryOpExpr); ok && unary.Op == hclsyntax.OpNegate {
+		sign, expr = "-", unary.Val
+	}
+	literal, ok := expr.(*hclsyntax.LiteralValueExpr)
+	if !ok || literal.Val.Type() != cty.Number || !literal.Val.IsKnown() {
+		return "", false
+	}
+	number := sign + c.rangeSource(literal.SrcRange)
+	if !json.Valid([]byte(number)) {
+		return "", false
+	}
+	return json.Number(number), true
+}
+
+// literal renders a known value, literal or evaluated, honouring
+// Options.ValueEncoder and Options.FormatNumber.
+func (c *Converter) literal(val cty.Value, r hcl.Range) (interface{}, error) {
+	if c.opts.ValueEncoder == nil && c.opts.FormatNumber == nil {
+		if number, ok := integerNumber(val); ok {
+			return number, nil
+		}
+		return ctyjson.SimpleJSONValue{Value: val}, nil
+	}
+
+	raw, err := c.encodeValue(val)
+	if err != nil {
+		return nil, newError(CodeInvalidValue, &r, "encode value", err)
+	}
+	return raw, nil
+}
+
+// integerNumber renders a number that fits an int64 the way ctyjson would,
+// without its decimal conversion of the big.Float, which dominates the cost
+// of encoding the common small integers.
+func integerNumber(val cty.Value) (json.Number, bool) {
+	if val.Type() != cty.Number || !val.IsKnown() || val.IsNull() || val.IsMarked() {
+		return "", false
+	}
+	f := val.AsBigFloat()
+	i, accuracy := f.Int64()
+	if accuracy != big.Exact || f.Signbit() && i == 0 {
+		return "", false
+	}
+	return json.Number(strconv.FormatInt(i, 10)), true
+}
+
+func (c *Converter) encodeValue(val cty.Value) (json.RawMessage, error) {
+	if c.opts.ValueEncoder != nil {
+		raw, ok, err := c.opts.ValueEncoder.EncodeValue(val)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if !json.Valid(raw) {
+				return nil, fmt.Errorf("value encoder returned invalid JSON %q", raw)
+			}
+			return raw, nil
+		}
+	}
+
+	ty := val.Type()
+	switch {
+	case val.IsNull() || !val.IsKnown():
+		return json.Marshal(ctyjson.SimpleJSONValue{Value: val})
+	case ty == cty.Number && c.opts.FormatNumber != nil:
+		raw, err := c.opts.FormatNumber(val)
+		if err != nil {
+			return nil, fmt.Errorf("format number: %w", err)
+		}
+		if !json.Valid(raw) {
+			return nil, fmt.Errorf("format number: invalid JSON %q", raw)
+		}
+		return raw, nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			raw, err := c.encodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(raw)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	case ty.IsMapType() || ty.IsObjectType():
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			raw, err := c.encodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			name, err := json.Marshal(key.AsString())
+			if err != nil {
+				return nil, err
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			buf.Write(name)
+			buf.WriteByte(':')
+			buf.Write(raw)
+		}
+		buf.WriteByte('}')
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(ctyjson.SimpleJSONValue{Value: val})
+}
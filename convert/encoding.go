@@ -0,0 +1,115 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Encoding selects the format of the encoded document.
+type Encoding int
+
+const (
+	// EncodingJSON encodes the document as JSON.
+	EncodingJSON Encoding = iota
+	// EncodingYAML encodes the document as YAML, block style, with the
+	// same keys, values and key order as the JSON.
+	EncodingYAML
+	// EncodingMsgpack encodes the document as MessagePack. Integers beyond
+	// 64 bits are encoded as strings.
+	EncodingMsgpack
+	// EncodingCBOR encodes the document as CBOR, with integers beyond 64
+	// bits as bignums.
+	EncodingCBOR
+	// EncodingTOML encodes the document as TOML. Since TOML has no null
+	// and orders pairs before tables, nulls are left out, scalar values
+	// are written ahead of blocks, and an array of objects, such as
+	// repeated blocks, becomes an array of tables: a single block and a
+	// list of one block encode differently, but an empty list just as an
+	// empty array. Integers beyond 64 bits are encoded as strings.
+	EncodingTOML
+)
+
+// transcoders re-encode the JSON of a document in the other encodings, so
+// that they share its wrapping, number formatting and key order.
+var transcoders = map[Encoding]func(jsonBytes []byte) ([]byte, error){
+	EncodingYAML:    jsonToYAML,
+	EncodingMsgpack: jsonToMsgpack,
+	EncodingCBOR:    jsonToCBOR,
+	EncodingTOML:    jsonToTOML,
+}
+
+func transcode(encoding Encoding, jsonBytes []byte) ([]byte, error) {
+	transcoder, ok := transcoders[encoding]
+	if !ok {
+		return nil, newError(CodeEncodeFailed, nil, fmt.Sprintf("unknown encoding %d", encoding), nil)
+	}
+	out, err := transcoder(jsonBytes)
+	if err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "transcode json", err)
+	}
+	return out, nil
+}
+
+// jsonQuote renders s as a JSON string without escaping HTML characters,
+// which YAML and TOML accept as double-quoted strings.
+func jsonQuote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// orderedObject is a decoded JSON object that keeps its key order.
+type orderedObject struct {
+	keys   []string
+	values []interface{}
+}
+
+// decodeOrdered decodes jsonBytes into orderedObject, []interface{},
+// json.Number, string, bool and nil values.
+func decodeOrdered(jsonBytes []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	return readOrdered(decoder)
+}
+
+func readOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := &orderedObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.keys = append(object.keys, key.(string))
+			object.values = append(object.values, value)
+		}
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		list := make([]interface{}, 0)
+		for decoder.More() {
+			value, err := readOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = decoder.Token()
+		return list, err
+	}
+	return token, nil
+}
@@ -0,0 +1,133 @@
+package convert
+
+import (
+	"errors"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// Code identifies a class of problem reported by the converter. Codes are
+// stable, so callers can suppress or route specific classes of problems
+// without matching on message text.
+type Code string
+
+const (
+	CodeUnsupportedExpression Code = "HCLJSON001"
+	CodeUnsupportedBody       Code = "HCLJSON002"
+	CodeInvalidValue          Code = "HCLJSON003"
+	CodeSyntaxError           Code = "HCLJSON004"
+	CodeInvalidJSON           Code = "HCLJSON005"
+	CodeEncodeFailed          Code = "HCLJSON006"
+	CodeKeyCollision          Code = "HCLJSON007"
+	CodeInvalidPath           Code = "HCLJSON008"
+	CodeBudgetExceeded        Code = "HCLJSON009"
+	CodeDialectViolation      Code = "HCLJSON010"
+	CodeMissingOverrideBase   Code = "HCLJSON011"
+	CodeInternal              Code = "HCLJSON012"
+)
+
+var codeSummaries = map[Code]string{
+	CodeUnsupportedExpression: "unsupported expression",
+	CodeUnsupportedBody:       "unsupported body",
+	CodeInvalidValue:          "invalid value",
+	CodeSyntaxError:           "syntax error",
+	CodeInvalidJSON:           "invalid JSON",
+	CodeEncodeFailed:          "encode failed",
+	CodeKeyCollision:          "key collision",
+	CodeInvalidPath:           "invalid path",
+	CodeBudgetExceeded:        "budget exceeded",
+	CodeDialectViolation:      "dialect violation",
+	CodeMissingOverrideBase:   "missing override base",
+	CodeInternal:              "internal error",
+}
+
+// Summary returns the short catalog description of the code.
+func (c Code) Summary() string {
+	return codeSummaries[c]
+}
+
+// Error is a conversion problem tagged with a stable Code.
+type Error struct {
+	Code    Code
+	Detail  string
+	Subject *hcl.Range
+	Err     error
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code) + " " + e.Code.Summary()
+	if e.Subject != nil {
+		msg = e.Subject.String() + ": " + msg
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the code of the first *Error found in err's chain, or an
+// empty code if there is none.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+func newError(code Code, subject *hcl.Range, detail string, err error) *Error {
+	return &Error{
+		Code:    code,
+		Detail:  detail,
+		Subject: subject,
+		Err:     err,
+	}
+}
+
+// Diagnostic returns e as an error diagnostic. The code is kept as the
+// summary prefix so that it survives in hcl.Diagnostics.
+func (e *Error) Diagnostic() *hcl.Diagnostic {
+	detail := e.Detail
+	if e.Err != nil {
+		if detail != "" {
+			detail += ": "
+		}
+		detail += e.Err.Error()
+	}
+
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  string(e.Code) + ": " + e.Code.Summary(),
+		Detail:   detail,
+		Subject:  e.Subject,
+	}
+}
+
+func errorDiagnostic(err error) *hcl.Diagnostic {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Diagnostic()
+	}
+
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "Conversion failed",
+		Detail:   err.Error(),
+	}
+}
+
+func warningDiagnostic(code Code, subject *hcl.Range, detail string) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  string(code) + ": " + code.Summary(),
+		Detail:   detail,
+		Subject:  subject,
+	}
+}
@@ -0,0 +1,91 @@
+package convert
+
+import (
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Estimate describes the shape of a conversion without performing it.
+type Estimate struct {
+	// Size is the approximate length of the JSON output in bytes.
+	Size int
+	// Blocks counts blocks by type, at every level.
+	Blocks map[string]int
+	// Attributes counts attributes at every level.
+	Attributes int
+	// MaxDepth is the deepest nesting of objects and arrays in the output.
+	MaxDepth int
+}
+
+// EstimateConversion parses the input and walks its syntax tree to
+// estimate the output of converting it, so that callers can route large
+// inputs before paying for the conversion. Expressions are measured by
+// their source length and never evaluated.
+func EstimateConversion(bytes []byte, filename string) (*Estimate, error) {
+	file, diags := hclsyntax.ParseConfig(bytes, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse config", diags)
+	}
+
+	e := &Estimate{Blocks: make(map[string]int)}
+	// the top-level object
+	e.Size = 2 + e.body(file.Body.(*hclsyntax.Body), 1)
+	return e, nil
+}
+
+// body returns the estimated size of the members of body, whose object is
+// at the given depth.
+func (e *Estimate) body(body *hclsyntax.Body, depth int) int {
+	if depth > e.MaxDepth {
+		e.MaxDepth = depth
+	}
+
+	size := 0
+	for _, attr := range body.Attributes {
+		e.Attributes++
+		// "name": value,
+		size += len(attr.Name) + 4 + e.expression(attr.Expr, depth)
+	}
+	for _, block := range body.Blocks {
+		e.Blocks[block.Type]++
+		// one nested object per label, each "key": {...},
+		keys := append([]string{block.Type}, block.Labels...)
+		for _, key := range keys {
+			size += len(key) + 6
+		}
+		size += e.body(block.Body, depth+len(keys))
+	}
+	return size
+}
+
+// expression returns the estimated size of expr, which is at the given
+// depth when it is an object or array.
+func (e *Estimate) expression(expr hclsyntax.Expression, depth int) int {
+	switch v := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		if depth+1 > e.MaxDepth {
+			e.MaxDepth = depth + 1
+		}
+		size := 2
+		for _, item := range v.Items {
+			size += e.expression(item.KeyExpr, depth+1) + 2 + e.expression(item.ValueExpr, depth+1)
+		}
+		return size
+	case *hclsyntax.TupleConsExpr:
+		if depth+1 > e.MaxDepth {
+			e.MaxDepth = depth + 1
+		}
+		size := 2
+		for _, elem := range v.Exprs {
+			size += e.expression(elem, depth+1) + 1
+		}
+		return size
+	case *hclsyntax.LiteralValueExpr, *hclsyntax.TemplateExpr, *hclsyntax.ObjectConsKeyExpr:
+		r := expr.Range()
+		return r.End.Byte - r.Start.Byte
+	}
+
+	// other expressions are wrapped as "${...}"
+	r := expr.Range()
+	return r.End.Byte - r.Start.Byte + 5
+}
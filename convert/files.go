@@ -0,0 +1,70 @@
+package convert
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sync"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// ConvertFiles reads and converts the files at paths with up to concurrency
+// workers, GOMAXPROCS if concurrency is not positive, and returns the
+// artifacts of every file keyed by its path. A file that fails to read or
+// convert still has its artifacts, holding the diagnostics. The error is
+// ctx's if it is done before all files are converted; the artifacts of the
+// files converted so far are returned along with it.
+func ConvertFiles(ctx context.Context, paths []string, concurrency int) (map[string]*Artifacts, error) {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		mu      sync.Mutex
+		results = make(map[string]*Artifacts, len(paths))
+		wg      sync.WaitGroup
+	)
+	jobs := make(chan string)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// a Converter must not be shared between goroutines
+			converter := NewConverter(Options{}).WithContext(ctx)
+			for path := range jobs {
+				artifacts := convertPath(converter, path)
+				mu.Lock()
+				results[path] = artifacts
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+send:
+	for _, path := range paths {
+		select {
+		case jobs <- path:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, err
+}
+
+func convertPath(converter *Converter, path string) *Artifacts {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return &Artifacts{Diagnostics: hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Failed to read file",
+			Detail:   err.Error(),
+		}}}
+	}
+	return converter.convertAll(src, path)
+}
@@ -0,0 +1,82 @@
+package convert
+
+import (
+	"path"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// blockSelected reports whether block is kept by Options.IncludeBlocks and
+// ExcludeBlocks, which only apply to top-level blocks.
+func (c *Converter) blockSelected(block *hclsyntax.Block) bool {
+	if len(c.blockTypes) > 0 {
+		return true
+	}
+	return c.addressSelected(append([]string{block.Type}, block.Labels...))
+}
+
+// addressSelected reports whether the top-level block of the given type
+// and labels is kept by Options.IncludeBlocks and ExcludeBlocks.
+func (c *Converter) addressSelected(address []string) bool {
+	if len(c.opts.IncludeBlocks) > 0 && !matchesAny(c.opts.IncludeBlocks, address) {
+		return false
+	}
+	return !matchesAny(c.opts.ExcludeBlocks, address)
+}
+
+// selectJSONBlocks drops the top-level blocks of a JSON syntax document
+// that are not kept by Options.IncludeBlocks and ExcludeBlocks, along with
+// the label objects left empty. Only the block types known from Terraform
+// have their labels told apart, and other keys are kept as attributes.
+func (c *Converter) selectJSONBlocks(document jsonObj) {
+	if len(c.opts.IncludeBlocks) == 0 && len(c.opts.ExcludeBlocks) == 0 {
+		return
+	}
+	for key := range document {
+		if labels, isBlock := overrideLabels[key]; isBlock && !c.selectJSONLabels(document, key, []string{key}, labels) {
+			delete(document, key)
+		}
+	}
+}
+
+// selectJSONLabels filters the blocks under obj[key], labels levels of
+// objects deep, and reports whether any is kept.
+func (c *Converter) selectJSONLabels(obj jsonObj, key string, address []string, labels int) bool {
+	if labels == 0 {
+		return c.addressSelected(address)
+	}
+	names, ok := obj[key].(jsonObj)
+	if !ok {
+		return c.addressSelected(address)
+	}
+	for name := range names {
+		if !c.selectJSONLabels(names, name, append(address[:len(address):len(address)], name), labels-1) {
+			delete(names, name)
+		}
+	}
+	return len(names) > 0
+}
+
+func matchesAny(patterns []string, address []string) bool {
+	for _, pattern := range patterns {
+		if matchesAddress(pattern, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAddress reports whether the segments of pattern match the leading
+// segments of address. Malformed patterns match nothing.
+func matchesAddress(pattern string, address []string) bool {
+	segments, err := ParsePath(pattern)
+	if err != nil || len(segments) == 0 || len(segments) > len(address) {
+		return false
+	}
+	for i, segment := range segments {
+		if ok, err := path.Match(segment, address[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
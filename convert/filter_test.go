@@ -0,0 +1,51 @@
+package convert
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSelectJSONSyntaxBlocks(t *testing.T) {
+	src := `{
+  "provider": {"aws": {"region": "eu-west-1"}},
+  "resource": {"aws_instance": {"web": {"ami": "a"}}, "google_instance": {"db": {}}},
+  "locals": [{"a": 1}],
+  "name": "kept"
+}`
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "include",
+			opts: Options{IncludeBlocks: []string{"resource.aws_*"}},
+			want: `{"name":"kept","resource":{"aws_instance":{"web":{"ami":"a"}}}}`,
+		},
+		{
+			name: "exclude",
+			opts: Options{ExcludeBlocks: []string{"provider", "locals", "resource.*.db"}},
+			want: `{"name":"kept","resource":{"aws_instance":{"web":{"ami":"a"}}}}`,
+		},
+		{
+			name: "none",
+			opts: Options{},
+			want: `{"locals":[{"a":1}],"name":"kept","provider":{"aws":{"region":"eu-west-1"}},"resource":{"aws_instance":{"web":{"ami":"a"}},"google_instance":{"db":{}}}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			artifacts, err := ConvertAll([]byte(src), "main.tf.json", test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(artifacts.Document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+//go:build go1.18
+// +build go1.18
+
+package convert
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+var fuzzSeeds = []string{
+	``,
+	`a = 1`,
+	`a = "${b}-c"`,
+	`a = (b + c) * d`,
+	`a = [for x in y : x if x != null]`,
+	`a = { for k, v in m : k => v... }`,
+	`a = b ? c : d`,
+	`a = f(x, y...)`,
+	`a = x[*].y`,
+	`a = <<EOT
+hello ${name}
+EOT`,
+	`resource "aws_instance" "web" {
+  ami = "ami-123"
+  tags = { Name = "web" }
+  lifecycle { create_before_destroy = true }
+}`,
+	`provider "aws" { region = "eu-west-1" }
+provider "aws" {
+  alias  = "us"
+  region = "us-east-1"
+}`,
+	`locals { a = 1 }
+locals { b = local.a }`,
+	`variable "a" {
+  type    = list(string)
+  default = ["x"]
+}`,
+	`a = "%{ for x in xs }${x}%{ endfor }"`,
+	`a {
+  b {
+  }
+}`,
+	`a = 1
+a = 2`,
+	`a = `,
+	`A00000000""A{}`,
+}
+
+// FuzzConvert checks that no input makes the converter panic.
+func FuzzConvert(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, src []byte) {
+		_, diags := SafeConvert(src, "fuzz.tf", Options{})
+		for _, diag := range diags {
+			if strings.HasPrefix(diag.Summary, string(CodeInternal)) {
+				t.Fatalf("%s: %s", diag.Summary, diag.Detail)
+			}
+		}
+	})
+}
+
+// FuzzRoundTrip checks that the conversions of native syntax input convert
+// back to native syntax that parses.
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, src []byte) {
+		// the parser lets some invalid UTF-8 through as identifiers
+		if !utf8.Valid(src) {
+			return
+		}
+		if _, diags := hclsyntax.ParseConfig(src, "fuzz.tf", hcl.Pos{Line: 1, Column: 1}); diags.HasErrors() {
+			return
+		}
+		converted, diags := SafeConvert(src, "fuzz.tf", Options{})
+		if diags.HasErrors() {
+			return
+		}
+		native, err := JsonToNativeHcl(converted)
+		if err != nil {
+			t.Fatalf("convert back %s: %v", converted, err)
+		}
+		if _, diags := hclsyntax.ParseConfig(native, "fuzz.tf", hcl.Pos{Line: 1, Column: 1}); diags.HasErrors() {
+			t.Fatalf("parse %s converted back from %s: %s", native, converted, diags)
+		}
+	})
+}
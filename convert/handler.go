@@ -0,0 +1,124 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// maxRequestBytes bounds the body a Handler reads.
+const maxRequestBytes = 10 << 20
+
+// handlerMaxDepth is the Options.MaxDepth of a Handler whose options leave
+// it zero, since a request may nest anything.
+const handlerMaxDepth = 100
+
+// contentTypes are the media types of the encodings a Handler responds in.
+var contentTypes = map[Encoding]string{
+	EncodingJSON:    "application/json",
+	EncodingYAML:    "application/yaml",
+	EncodingMsgpack: "application/msgpack",
+	EncodingCBOR:    "application/cbor",
+	EncodingTOML:    "application/toml",
+}
+
+// NewHandler returns an http.Handler converting the body of POST requests
+// the way the hcljson command converts a file: native syntax to a document
+// encoded as set by opts, JSON back to native syntax. The optional
+// filename query parameter names the input, which decides its format
+// like a file name would. Errors are reported with a 4xx status and a
+// JSON body {"diagnostics": [...]}, as encoded by DiagnosticsToJSON.
+// Conversions stop once the request's context is done, and a zero
+// opts.MaxDepth is set to 100; a negative one lifts the bound.
+func NewHandler(opts Options) http.Handler {
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = handlerMaxDepth
+	}
+	return &handler{opts: opts, pool: NewConverterPool(opts)}
+}
+
+type handler struct {
+	opts Options
+	pool *ConverterPool
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeDiagnostics(w, http.StatusMethodNotAllowed, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Method not allowed",
+			Detail:   "Send the input in the body of a POST request.",
+		}}, nil)
+		return
+	}
+
+	// read one byte past the limit to tell a body too large from one that
+	// fits exactly
+	src, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes+1))
+	if err != nil {
+		writeDiagnostics(w, http.StatusBadRequest, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Unable to read request",
+			Detail:   err.Error(),
+		}}, nil)
+		return
+	}
+	if len(src) > maxRequestBytes {
+		writeDiagnostics(w, http.StatusRequestEntityTooLarge, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  "Request too large",
+			Detail:   fmt.Sprintf("The body of a request is limited to %d bytes.", maxRequestBytes),
+		}}, nil)
+		return
+	}
+	filename := r.URL.Query().Get("filename")
+	if filename == "" {
+		filename = "<request>"
+	}
+	files := map[string]*hcl.File{filename: {Bytes: src}}
+
+	if DetectFormat(src, filename) != FormatHCL {
+		result, err := JsonToNativeHcl(src)
+		if err != nil {
+			writeDiagnostics(w, http.StatusUnprocessableEntity, hcl.Diagnostics{errorDiagnostic(err)}, files)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(result)
+		return
+	}
+
+	var result []byte
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".tfvars") {
+		result, diags = ConvertTfvars(src, filename, h.opts)
+		if !diags.HasErrors() && h.opts.Encoding != EncodingJSON {
+			// ConvertTfvars always encodes JSON
+			var err error
+			if result, err = transcode(h.opts.Encoding, result); err != nil {
+				diags = append(diags, errorDiagnostic(err))
+			}
+		}
+	} else {
+		converter := h.pool.Get().WithContext(r.Context())
+		result, diags = converter.SafeConvert(src, filename)
+		h.pool.Put(converter)
+	}
+	if diags.HasErrors() {
+		writeDiagnostics(w, http.StatusUnprocessableEntity, diags, files)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[h.opts.Encoding])
+	w.Write(result)
+}
+
+func writeDiagnostics(w http.ResponseWriter, status int, diags hcl.Diagnostics, files map[string]*hcl.File) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"diagnostics": diagnosticsJSON(diags, files)})
+}
@@ -0,0 +1,121 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerTfvarsEncoding(t *testing.T) {
+	handler := NewHandler(Options{Encoding: EncodingYAML})
+	request := httptest.NewRequest(http.MethodPost, "/?filename=dev.tfvars", strings.NewReader(`region = "eu-west-1"`))
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", response.Code, response.Body)
+	}
+	if got, want := response.Header().Get("Content-Type"), "application/yaml"; got != want {
+		t.Errorf("got Content-Type %s, want %s", got, want)
+	}
+	if got, want := response.Body.String(), "region: eu-west-1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestHandlerRequestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   func() *http.Request
+		status int
+	}{
+		{
+			name: "too large",
+			body: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat(" ", maxRequestBytes+1)))
+			},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "read error",
+			body: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/", failingReader{})
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "method",
+			body: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/", nil)
+			},
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			NewHandler(Options{}).ServeHTTP(response, test.body())
+			if response.Code != test.status {
+				t.Errorf("got status %d, want %d: %s", response.Code, test.status, response.Body)
+			}
+		})
+	}
+}
+
+func TestHandlerLimits(t *testing.T) {
+	nested := `a = length(flatten([for i in range(150): [for j in range(150): [for k in range(150): k]]]))`
+	tests := []struct {
+		name     string
+		opts     Options
+		filename string
+		body     string
+		cancel   bool
+		want     string
+	}{
+		{
+			name:   "cancelled request",
+			opts:   Options{Simplify: true, TerraformFunctions: true, MaxExpansion: -1},
+			body:   nested,
+			cancel: true,
+			want:   context.Canceled.Error(),
+		},
+		{
+			name: "default depth",
+			body: "a = " + strings.Repeat("[", 200) + strings.Repeat("]", 200),
+			want: string(CodeBudgetExceeded),
+		},
+		{
+			name:     "default depth of tfvars",
+			filename: "dev.tfvars",
+			body:     "a = " + strings.Repeat("[", 200) + strings.Repeat("]", 200),
+			want:     string(CodeBudgetExceeded),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/?filename="+test.filename, strings.NewReader(test.body))
+			if test.cancel {
+				ctx, cancel := context.WithCancel(request.Context())
+				cancel()
+				request = request.WithContext(ctx)
+			}
+			response := httptest.NewRecorder()
+			NewHandler(test.opts).ServeHTTP(response, request)
+			if response.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("got status %d, want %d: %s", response.Code, http.StatusUnprocessableEntity, response.Body)
+			}
+			if !strings.Contains(response.Body.String(), test.want) {
+				t.Errorf("got %s, want %s", response.Body, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// convertJSONBody converts the document of a file in HCL JSON syntax, which
+// is already in the shape produced for native syntax, so values are kept as
+// is but for Redact, RedactSensitive and Simplify, and blocks are filtered
+// by IncludeBlocks and ExcludeBlocks. The labels of the top-level blocks
+// are told apart from attributes by the block types known from Terraform.
+// Options that only apply to native syntax expressions, as listed by
+// unsupportedJSONOption, fail the conversion.
+func (c *Converter) convertJSONBody(file *hcl.File) (jsonObj, error) {
+	var document jsonObj
+	decoder := json.NewDecoder(bytes.NewReader(file.Bytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, newError(CodeUnsupportedBody, nil, fmt.Sprintf("%T is neither a native nor a JSON syntax body", file.Body), err)
+	}
+	if document == nil {
+		document = make(jsonObj)
+	}
+	if option := unsupportedJSONOption(c.opts); option != "" {
+		return nil, newError(CodeUnsupportedBody, nil, option+" cannot be applied to JSON syntax input", nil)
+	}
+	c.selectJSONBlocks(document)
+
+	for key, value := range document {
+		labels, isBlock := overrideLabels[key]
+		if !isBlock {
+			if err := c.convertJSONAttribute(document, key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		leave := c.enterPath(key)
+		err := c.convertJSONBlocks(value, key, labels)
+		leave()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return document, nil
+}
+
+// convertJSONBlocks converts the blocks of type blockType under value,
+// labels levels of objects above their bodies.
+func (c *Converter) convertJSONBlocks(value interface{}, blockType string, labels int) error {
+	if labels > 0 {
+		names, _ := value.(jsonObj)
+		for name, nested := range names {
+			leave := c.enterPath(name)
+			err := c.convertJSONBlocks(nested, blockType, labels-1)
+			leave()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch v := value.(type) {
+	case jsonObj:
+		return c.convertJSONBlockBody(v, blockType)
+	case []interface{}:
+		for i, item := range v {
+			body, ok := item.(jsonObj)
+			if !ok {
+				continue
+			}
+			leave := c.enterPath(strconv.Itoa(i))
+			err := c.convertJSONBlockBody(body, blockType)
+			leave()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// convertJSONBlockBody converts the body of a top-level block.
+func (c *Converter) convertJSONBlockBody(body jsonObj, blockType string) error {
+	sensitive := c.opts.RedactSensitive && sensitiveValues[blockType] != "" && body["sensitive"] == true
+	for key := range body {
+		if sensitive && key == sensitiveValues[blockType] {
+			leave := c.enterPath(key)
+			var err error
+			body[key], err = c.redact(body[key])
+			leave()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.convertJSONAttribute(body, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertJSONAttribute converts the value of obj[key], redacting it if its
+// path matches Options.Redact. Nested blocks cannot be told apart from
+// object values in JSON syntax, so both are matched.
+func (c *Converter) convertJSONAttribute(obj jsonObj, key string) error {
+	leave := c.enterPath(key)
+	defer leave()
+
+	value, err := c.convertJSONValue(obj[key])
+	if err == nil && c.pathMatches(c.opts.Redact) {
+		value, err = c.redact(value)
+	}
+	if err != nil {
+		return err
+	}
+	obj[key] = value
+	return nil
+}
+
+// convertJSONValue converts a value of a JSON syntax document: the
+// attributes of objects are converted in turn, and strings holding a
+// template are resolved by Options.Simplify when they can be, like their
+// native syntax counterparts.
+func (c *Converter) convertJSONValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return c.simplifiedJSONString(v)
+	case jsonObj:
+		for key := range v {
+			if err := c.convertJSONAttribute(v, key); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			leave := c.enterPath(strconv.Itoa(i))
+			converted, err := c.convertJSONValue(item)
+			leave()
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	}
+	return value, nil
+}
+
+// simplifiedJSONString returns the value of s, read as a template the way
+// the JSON syntax reads strings, if Simplify resolves it, or s itself.
+func (c *Converter) simplifiedJSONString(s string) (interface{}, error) {
+	if !strings.Contains(s, "${") && !strings.Contains(s, "%{") {
+		return s, nil
+	}
+	expr, diags := hclsyntax.ParseTemplate([]byte(s), c.path.String(), hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return s, nil
+	}
+	val, ok := c.simplified(expr)
+	if !ok {
+		return s, nil
+	}
+	return c.literal(val, expr.Range())
+}
+
+// unsupportedJSONOption returns the name of the first of opts that only
+// applies to expressions in native syntax, so that JSON syntax input is
+// refused instead of silently converted without it.
+func unsupportedJSONOption(opts Options) string {
+	switch {
+	case opts.StructuredReferences:
+		return "StructuredReferences"
+	case opts.ExprMode != ExprModeTemplate:
+		return "ExprMode"
+	case len(opts.ContextValues) > 0:
+		return "ContextValues"
+	case len(opts.Durations) > 0 || len(opts.Sizes) > 0:
+		return "Durations and Sizes"
+	case len(opts.Defaults) > 0:
+		return "Defaults"
+	case opts.InlineLocals:
+		return "InlineLocals"
+	case opts.FormatNumber != nil || opts.ValueEncoder != nil:
+		return "FormatNumber and ValueEncoder"
+	case opts.BeforeAttribute != nil || opts.BeforeBlock != nil || opts.AfterAttribute != nil || opts.AfterBlock != nil:
+		return "BeforeAttribute, BeforeBlock, AfterAttribute and AfterBlock"
+	case len(opts.ExpressionHandlers) > 0:
+		return "ExpressionHandlers"
+	}
+	return ""
+}
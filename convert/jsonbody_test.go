@@ -0,0 +1,46 @@
+package convert
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSimplifyJSONSyntax(t *testing.T) {
+	opts := Options{Simplify: true, Variables: map[string]cty.Value{"region": cty.StringVal("eu-west-1")}}
+	src := `{
+  "resource": {"a": {"b": {"count": "${1 + 2}", "zone": "${var.region}a", "ami": "${var.ami}", "tags": ["${max(1, 2)}", "y"]}}},
+  "locals": {"plain": "no template", "broken": "${"}
+}`
+	artifacts, err := ConvertAll([]byte(src), "main.tf.json", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(artifacts.Document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"locals":{"broken":"${","plain":"no template"},"resource":{"a":{"b":{"ami":"${var.ami}","count":3,"tags":[2,"y"],"zone":"eu-west-1a"}}}}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestUnsupportedJSONSyntaxOptions(t *testing.T) {
+	for _, opts := range []Options{
+		{StructuredReferences: true},
+		{ExprMode: ExprModeAST},
+		{InlineLocals: true},
+		{Durations: []string{"timeout"}},
+		{AfterAttribute: func(p Path, v interface{}) (interface{}, bool) { return v, true }},
+	} {
+		file, diags := parseFile([]byte(`{"a": "${b}"}`), "main.tf.json")
+		if diags.HasErrors() {
+			t.Fatal(diags)
+		}
+		if _, err := NewConverter(opts).ConvertFile(file); CodeOf(err) != CodeUnsupportedBody {
+			t.Errorf("got %v, want %s", err, CodeUnsupportedBody)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package convert
+
+import (
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// resolveLocals evaluates the locals of the top-level locals blocks of body
+// that only depend on what Simplify can resolve and on each other, for
+// Options.InlineLocals. References to them resolve to their values from
+// then on, and they are left out of the output.
+func (c *Converter) resolveLocals(body *hclsyntax.Body) {
+	if !c.opts.InlineLocals {
+		return
+	}
+
+	pending := map[string]hcl.Expression{}
+	for _, block := range body.Blocks {
+		if block.Type != "locals" {
+			continue
+		}
+		for name, attr := range block.Body.Attributes {
+			if _, exists := pending[name]; !exists {
+				pending[name] = attr.Expr
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx := &hcl.EvalContext{Functions: c.evalCtx.Functions, Variables: make(map[string]cty.Value, len(c.evalCtx.Variables)+1)}
+	for name, val := range c.evalCtx.Variables {
+		ctx.Variables[name] = val
+	}
+	// resolve in as many passes as it takes for references between locals
+	// to settle, like moduleVariables
+	locals := map[string]cty.Value{}
+	for progress := true; progress && len(pending) > 0; {
+		progress = false
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+		for name, expr := range pending {
+			restore := c.chargeIterations(expr)
+			val, diags := expr.Value(ctx)
+			restore()
+			if diags.HasErrors() || !val.IsWhollyKnown() {
+				continue
+			}
+			locals[name] = val
+			c.inlinedLocals[name] = true
+			delete(pending, name)
+			progress = true
+		}
+	}
+	for name := range pending {
+		locals[name] = cty.DynamicVal
+	}
+	ctx.Variables["local"] = cty.ObjectVal(locals)
+	c.evalCtx = ctx
+}
+
+// isInlinedLocal reports whether attr is a local inlined by resolveLocals.
+func (c *Converter) isInlinedLocal(attr *hclsyntax.Attribute) bool {
+	return len(c.blockTypes) == 1 && c.blockTypes[0] == "locals" && c.inlinedLocals[attr.Name]
+}
+
+// allLocalsInlined reports whether block is a top-level locals block whose
+// locals were all inlined, so that it is left out.
+func (c *Converter) allLocalsInlined(block *hclsyntax.Block) bool {
+	if !c.opts.InlineLocals || block.Type != "locals" || len(c.blockTypes) != 0 || len(block.Body.Attributes) == 0 || len(block.Body.Blocks) > 0 {
+		return false
+	}
+	for name := range block.Body.Attributes {
+		if !c.inlinedLocals[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package convert
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Logger receives debug messages. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// logExpr logs the kind and range of expr. Unlike logf it boxes nothing
+// when there is no logger, as it is called for every expression.
+func (c *Converter) logExpr(kind string, expr hclsyntax.Expression) {
+	if c.opts.Logger != nil {
+		c.opts.Logger.Printf("%s: %v", kind, expr.Range())
+	}
+}
+
+func (c *Converter) logf(format string, v ...interface{}) {
+	if c.opts.Logger != nil {
+		c.opts.Logger.Printf(format, v...)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build go1.21
+// +build go1.21
+
+package convert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// SlogLogger returns a Logger writing debug messages to l.
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Printf(format string, v ...interface{}) {
+	s.l.Log(context.Background(), slog.LevelDebug, fmt.Sprintf(format, v...))
+}
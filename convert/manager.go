@@ -0,0 +1,169 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ManagerLimits are the per-tenant limits of a Manager. Zero means no limit.
+type ManagerLimits struct {
+	// MaxConcurrent is the number of conversions a tenant may run at once.
+	MaxConcurrent int
+	// MaxQueued is the number of conversions a tenant may have waiting for
+	// a slot. Further requests are rejected.
+	MaxQueued int
+	// MaxBytesInFlight bounds the total input size of a tenant's running
+	// conversions, as a proxy for the memory they hold.
+	MaxBytesInFlight int64
+}
+
+// TenantMetrics are the counters a Manager keeps for one tenant.
+type TenantMetrics struct {
+	Active        int
+	Queued        int
+	BytesInFlight int64
+
+	Conversions   uint64
+	Failures      uint64
+	Rejected      uint64
+	TotalBytes    int64
+	TotalDuration time.Duration
+}
+
+// Manager runs conversions on behalf of tenants, enforcing the same limits
+// for each of them so that one tenant cannot starve the others. It is safe
+// for concurrent use.
+type Manager struct {
+	limits ManagerLimits
+
+	mu      sync.Mutex
+	tenants map[string]*tenantState
+}
+
+type tenantState struct {
+	metrics TenantMetrics
+	// closed and replaced whenever a conversion finishes
+	released chan struct{}
+}
+
+// NewManager returns a Manager applying limits to every tenant.
+func NewManager(limits ManagerLimits) *Manager {
+	return &Manager{
+		limits:  limits,
+		tenants: make(map[string]*tenantState),
+	}
+}
+
+// Convert converts bytes for tenant, waiting for a slot if the tenant is at
+// its limits. It fails if the tenant's queue is full, the input alone
+// exceeds MaxBytesInFlight or ctx is done before the conversion finishes.
+func (m *Manager) Convert(ctx context.Context, tenant string, bytes []byte, filename string, opts Options) (*Artifacts, error) {
+	size := int64(len(bytes))
+	if err := m.acquire(ctx, tenant, size); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	artifacts, err := NewConverter(opts).WithContext(ctx).ConvertAll(bytes, filename)
+	m.release(tenant, size, time.Since(start), err)
+
+	return artifacts, err
+}
+
+// Metrics returns the counters of tenant.
+func (m *Manager) Metrics(tenant string) TenantMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.tenants[tenant]; ok {
+		return t.metrics
+	}
+	return TenantMetrics{}
+}
+
+// AllMetrics returns the counters of every tenant seen so far.
+func (m *Manager) AllMetrics() map[string]TenantMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	all := make(map[string]TenantMetrics, len(m.tenants))
+	for name, t := range m.tenants {
+		all[name] = t.metrics
+	}
+	return all
+}
+
+func (m *Manager) tenant(name string) *tenantState {
+	t, ok := m.tenants[name]
+	if !ok {
+		t = &tenantState{released: make(chan struct{})}
+		m.tenants[name] = t
+	}
+	return t
+}
+
+func (m *Manager) fits(t *tenantState, size int64) bool {
+	if m.limits.MaxConcurrent > 0 && t.metrics.Active >= m.limits.MaxConcurrent {
+		return false
+	}
+	if m.limits.MaxBytesInFlight > 0 && t.metrics.BytesInFlight+size > m.limits.MaxBytesInFlight {
+		return false
+	}
+	return true
+}
+
+func (m *Manager) acquire(ctx context.Context, tenant string, size int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.tenant(tenant)
+
+	if m.limits.MaxBytesInFlight > 0 && size > m.limits.MaxBytesInFlight {
+		t.metrics.Rejected++
+		return newError(CodeBudgetExceeded, nil,
+			fmt.Sprintf("input of %d bytes exceeds the limit of %d for tenant %q", size, m.limits.MaxBytesInFlight, tenant), nil)
+	}
+
+	if !m.fits(t, size) {
+		if m.limits.MaxQueued > 0 && t.metrics.Queued >= m.limits.MaxQueued {
+			t.metrics.Rejected++
+			return newError(CodeBudgetExceeded, nil, fmt.Sprintf("queue of tenant %q is full", tenant), nil)
+		}
+
+		t.metrics.Queued++
+		for !m.fits(t, size) {
+			released := t.released
+			m.mu.Unlock()
+			select {
+			case <-released:
+				m.mu.Lock()
+			case <-ctx.Done():
+				m.mu.Lock()
+				t.metrics.Queued--
+				return ctx.Err()
+			}
+		}
+		t.metrics.Queued--
+	}
+
+	t.metrics.Active++
+	t.metrics.BytesInFlight += size
+	return nil
+}
+
+func (m *Manager) release(tenant string, size int64, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.tenant(tenant)
+
+	t.metrics.Active--
+	t.metrics.BytesInFlight -= size
+	t.metrics.Conversions++
+	t.metrics.TotalBytes += size
+	t.metrics.TotalDuration += elapsed
+	if err != nil {
+		t.metrics.Failures++
+	}
+
+	close(t.released)
+	t.released = make(chan struct{})
+}
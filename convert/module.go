@@ -0,0 +1,219 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ModuleInterface is the compact public interface of a Terraform module,
+// suitable for module registries and documentation generators.
+type ModuleInterface struct {
+	Variables []ModuleVariable `json:"variables"`
+	Outputs   []ModuleOutput   `json:"outputs"`
+}
+
+// ModuleVariable describes one input variable of a module.
+type ModuleVariable struct {
+	Name string `json:"name"`
+	// Type is the source text of the type constraint, e.g. "list(string)".
+	Type string `json:"type,omitempty"`
+	// Default is the converted default value.
+	Default     interface{} `json:"default,omitempty"`
+	Required    bool        `json:"required"`
+	Description string      `json:"description,omitempty"`
+	Sensitive   bool        `json:"sensitive,omitempty"`
+}
+
+// ModuleOutput describes one output value of a module.
+type ModuleOutput struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Sensitive   bool   `json:"sensitive,omitempty"`
+}
+
+// moduleSchema picks the blocks of a module that ModuleInterface and
+// console evaluation read, from native and JSON syntax files alike.
+var moduleSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "output", LabelNames: []string{"name"}},
+		{Type: "locals"},
+	},
+}
+
+// variableSchema and outputSchema pick the attributes of variable and
+// output blocks that ModuleInterface describes.
+var (
+	variableSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "type"}, {Name: "default"}, {Name: "description"}, {Name: "sensitive"}},
+	}
+	outputSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "description"}, {Name: "sensitive"}},
+	}
+)
+
+// moduleBlocks returns the variable, output and locals blocks of file.
+// Blocks with the wrong number of labels are left out.
+func moduleBlocks(file *hcl.File) hcl.Blocks {
+	content, _, _ := file.Body.PartialContent(moduleSchema)
+	return content.Blocks
+}
+
+// GetModuleInterface reads the .tf and .tf.json files directly in dir and
+// summarizes the variables and outputs they declare, sorted by name.
+func GetModuleInterface(fsys fs.FS, dir string) (*ModuleInterface, error) {
+	files, err := parseModule(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	iface := &ModuleInterface{
+		Variables: []ModuleVariable{},
+		Outputs:   []ModuleOutput{},
+	}
+	c := NewConverter(Options{})
+	for _, file := range files {
+		c.bytes = file.Bytes
+		for _, block := range moduleBlocks(file) {
+			switch block.Type {
+			case "variable":
+				variable, err := c.moduleVariable(block)
+				if err != nil {
+					return nil, err
+				}
+				iface.Variables = append(iface.Variables, variable)
+			case "output":
+				attrs, _, _ := block.Body.PartialContent(outputSchema)
+				iface.Outputs = append(iface.Outputs, ModuleOutput{
+					Name:        block.Labels[0],
+					Description: literalString(attrs.Attributes, "description"),
+					Sensitive:   literalBool(attrs.Attributes, "sensitive"),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(iface.Variables, func(i, j int) bool {
+		return iface.Variables[i].Name < iface.Variables[j].Name
+	})
+	sort.SliceStable(iface.Outputs, func(i, j int) bool {
+		return iface.Outputs[i].Name < iface.Outputs[j].Name
+	})
+
+	return iface, nil
+}
+
+// parseModule parses the .tf and .tf.json files directly in dir, in name
+// order.
+func parseModule(fsys fs.FS, dir string) ([]*hcl.File, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*hcl.File
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tf") && !strings.HasSuffix(entry.Name(), ".tf.json") {
+			continue
+		}
+
+		filename := path.Join(dir, entry.Name())
+		src, err := fs.ReadFile(fsys, filename)
+		if err != nil {
+			return nil, err
+		}
+		file, diags := parseFile(src, filename)
+		if diags.HasErrors() {
+			return nil, newError(CodeSyntaxError, diags[0].Subject, "parse config", diags)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (c *Converter) moduleVariable(block *hcl.Block) (ModuleVariable, error) {
+	attrs, _, _ := block.Body.PartialContent(variableSchema)
+	variable := ModuleVariable{
+		Name:        block.Labels[0],
+		Required:    true,
+		Description: literalString(attrs.Attributes, "description"),
+		Sensitive:   literalBool(attrs.Attributes, "sensitive"),
+	}
+
+	if attr, exists := attrs.Attributes["type"]; exists {
+		if _, native := attr.Expr.(hclsyntax.Expression); native {
+			variable.Type = c.rangeSource(attr.Expr.Range())
+		} else {
+			// the JSON syntax writes type constraints as strings, which
+			// HclToJson wraps in "${...}"
+			variable.Type = literalString(attrs.Attributes, "type")
+			if expr, ok := nativeWrappedExpr(variable.Type); ok {
+				variable.Type = expr
+			}
+		}
+	}
+	if attr, exists := attrs.Attributes["default"]; exists {
+		value, err := c.moduleDefault(attr.Expr)
+		if err != nil {
+			return variable, err
+		}
+		variable.Default = value
+		variable.Required = false
+	}
+
+	return variable, nil
+}
+
+// moduleDefault converts the default value of a variable. Values in JSON
+// syntax are already converted, so they are taken from the source as is.
+func (c *Converter) moduleDefault(expr hcl.Expression) (interface{}, error) {
+	if native, ok := expr.(hclsyntax.Expression); ok {
+		return c.ConvertExpression(native)
+	}
+
+	r := expr.Range()
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(c.bytes[r.Start.Byte:r.End.Byte]))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, newError(CodeInvalidJSON, &r, "decode default", err)
+	}
+	return value, nil
+}
+
+// literalString returns the value of a string attribute that does not
+// depend on anything, or "" otherwise.
+func literalString(attrs hcl.Attributes, name string) string {
+	value, ok := literalValue(attrs, name)
+	if !ok || value.Type() != cty.String {
+		return ""
+	}
+	return value.AsString()
+}
+
+// literalBool returns the value of a bool attribute that does not depend
+// on anything, or false otherwise.
+func literalBool(attrs hcl.Attributes, name string) bool {
+	value, ok := literalValue(attrs, name)
+	return ok && value.Type() == cty.Bool && value.True()
+}
+
+func literalValue(attrs hcl.Attributes, name string) (cty.Value, bool) {
+	attr, exists := attrs[name]
+	if !exists {
+		return cty.NilVal, false
+	}
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() {
+		return cty.NilVal, false
+	}
+	return value, true
+}
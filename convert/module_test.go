@@ -0,0 +1,64 @@
+package convert
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGetModuleInterface(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/variables.tf": {Data: []byte(`variable "region" {
+  type        = string
+  default     = "eu-west-1"
+  description = "The region."
+}
+
+variable "names" {
+  type = list(string)
+}
+`)},
+		"m/outputs.tf.json": {Data: []byte(`{
+  "variable": {
+    "count": {"type": "number", "default": 2},
+    "tags": {"type": "${map(string)}", "default": {"Name": "web"}, "sensitive": true}
+  },
+  "output": {
+    "id": {"value": "${aws_instance.web.id}", "description": "The id.", "sensitive": true}
+  }
+}`)},
+		"m/README.md": {Data: []byte(`# m`)},
+	}
+
+	iface, err := GetModuleInterface(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(iface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"variables":[` +
+		`{"name":"count","type":"number","default":2,"required":false},` +
+		`{"name":"names","type":"list(string)","required":true},` +
+		`{"name":"region","type":"string","default":"eu-west-1","required":false,"description":"The region."},` +
+		`{"name":"tags","type":"map(string)","default":{"Name":"web"},"required":false,"sensitive":true}],` +
+		`"outputs":[{"name":"id","description":"The id.","sensitive":true}]}`
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestConsoleJSONModule(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/main.tf.json": {Data: []byte(`{"variable": {"a": {"default": 2}}, "locals": {"b": "${var.a * 3}"}}`)},
+	}
+	got, err := Console("local.b + 1", fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(got)) != "7" {
+		t.Errorf("got %q, want 7", got)
+	}
+}
@@ -0,0 +1,165 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ToMap is like HclToJson, but returns the document instead of encoding
+// it, with values as encoding/json would decode the JSON: objects are
+// map[string]interface{}, arrays []interface{}, and numbers json.Number.
+func ToMap(bytes []byte, filename string) (map[string]interface{}, error) {
+	artifacts := NewConverter(Options{}).convertDocument(bytes, filename)
+	if artifacts.Document == nil {
+		return nil, artifacts.Diagnostics
+	}
+
+	document, err := nativeValue(artifacts.Document)
+	if err != nil {
+		return nil, err
+	}
+	return document.(jsonObj), nil
+}
+
+// ToCty is like ToMap, but returns the document as a cty object. Literals
+// keep the cty value they were parsed to; objects become cty objects,
+// arrays tuples and untyped nulls cty.DynamicPseudoType nulls.
+func ToCty(bytes []byte, filename string) (cty.Value, error) {
+	artifacts := NewConverter(Options{}).convertDocument(bytes, filename)
+	if artifacts.Document == nil {
+		return cty.NilVal, artifacts.Diagnostics
+	}
+	return ctyValue(artifacts.Document)
+}
+
+func ctyValue(value interface{}) (cty.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case bool:
+		return cty.BoolVal(v), nil
+	case string:
+		return cty.StringVal(v), nil
+	case json.Number:
+		val, err := cty.ParseNumberVal(v.String())
+		if err != nil {
+			return cty.NilVal, newError(CodeInvalidValue, nil, fmt.Sprintf("parse number %s", v), err)
+		}
+		return val, nil
+	case ctyjson.SimpleJSONValue:
+		return v.Value, nil
+	case jsonObj:
+		attributes := make(map[string]cty.Value, len(v))
+		for key, item := range v {
+			val, err := ctyValue(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attributes[key] = val
+		}
+		return cty.ObjectVal(attributes), nil
+	case []interface{}:
+		elements := make([]cty.Value, len(v))
+		for i, item := range v {
+			val, err := ctyValue(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			elements[i] = val
+		}
+		return cty.TupleVal(elements), nil
+	case json.RawMessage:
+		native, err := nativeValue(v)
+		if err != nil {
+			return cty.NilVal, err
+		}
+		return ctyValue(native)
+	}
+	return cty.NilVal, newError(CodeInvalidValue, nil, fmt.Sprintf("cannot represent %T as a cty value", value), nil)
+}
+
+// nativeValue replaces the cty values and raw JSON left in a converted
+// document with plain Go values.
+func nativeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case jsonObj:
+		out := make(jsonObj, len(v))
+		for key, item := range v {
+			native, err := nativeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = native
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			native, err := nativeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = native
+		}
+		return out, nil
+	case ctyjson.SimpleJSONValue:
+		return ctyNative(v.Value)
+	case json.RawMessage:
+		var native interface{}
+		decoder := json.NewDecoder(bytes.NewReader(v))
+		decoder.UseNumber()
+		if err := decoder.Decode(&native); err != nil {
+			return nil, newError(CodeInvalidValue, nil, "decode encoded value", err)
+		}
+		return native, nil
+	}
+	return value, nil
+}
+
+// ctyNative converts val the way ctyjson.SimpleJSONValue encodes it.
+func ctyNative(val cty.Value) (interface{}, error) {
+	val, _ = val.Unmark()
+	if !val.IsKnown() {
+		return nil, newError(CodeInvalidValue, nil, "value is not known", nil)
+	}
+	if val.IsNull() {
+		return nil, nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString(), nil
+	case ty == cty.Bool:
+		return val.True(), nil
+	case ty == cty.Number:
+		return json.Number(val.AsBigFloat().Text('f', -1)), nil
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		out := make([]interface{}, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			native, err := ctyNative(elem)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, native)
+		}
+		return out, nil
+	case ty.IsMapType() || ty.IsObjectType():
+		out := make(jsonObj, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			native, err := ctyNative(elem)
+			if err != nil {
+				return nil, err
+			}
+			out[key.AsString()] = native
+		}
+		return out, nil
+	}
+	return nil, newError(CodeInvalidValue, nil, fmt.Sprintf("cannot represent %s in JSON", ty.FriendlyName()), nil)
+}
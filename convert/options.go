@@ -0,0 +1,300 @@
+package convert
+
+import (
+	"encoding/json"
+	"reflect"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TransformFunc is called with the path of an attribute or block and its
+// value, and returns the value to use instead and whether to keep it.
+type TransformFunc func(path Path, value interface{}) (interface{}, bool)
+
+// EmptyBlockMode selects how blocks without any content, such as
+// `lifecycle {}`, are represented in the output.
+type EmptyBlockMode int
+
+const (
+	// EmptyBlockObject represents empty blocks as {}.
+	EmptyBlockObject EmptyBlockMode = iota
+	// EmptyBlockNull represents empty blocks as null.
+	EmptyBlockNull
+	// EmptyBlockOmit leaves empty blocks out of the output.
+	EmptyBlockOmit
+)
+
+// BlockShape selects whether the blocks of a type are represented as an
+// array or an object.
+type BlockShape int
+
+const (
+	// BlockShapeAuto represents a block as an object while its address is
+	// unique, and promotes it to an array once another block repeats it.
+	BlockShapeAuto BlockShape = iota
+	// BlockShapeArray always represents blocks as an array.
+	BlockShapeArray
+	// BlockShapeObject always represents blocks as a single object; the
+	// bodies of repeated blocks are merged, as Terraform does for locals.
+	// Repeated blocks setting the same attribute are a key collision.
+	BlockShapeObject
+)
+
+// Options controls how HCL is converted to JSON. The zero value matches the
+// behavior of HclToJson.
+type Options struct {
+	// Tolerant converts the parseable portions of a file with syntax errors
+	// instead of failing. Expressions in invalid regions are replaced with
+	// placeholder objects and the parse diagnostics are returned alongside
+	// the partial JSON.
+	Tolerant bool
+
+	// CollectErrors keeps converting past attributes and blocks that fail
+	// to convert, leaving them out of the JSON and reporting every failure
+	// as an error diagnostic instead of stopping at the first one.
+	CollectErrors bool
+
+	// Lenient never fails on an expression: one that fails to convert is
+	// replaced with a placeholder object, {"__error__": ..., "__source__":
+	// ...}, and reported as a warning and a SkippedItem. Blocks that fail
+	// to convert are left out as with CollectErrors.
+	Lenient bool
+
+	// Strict fails on content that is otherwise merged or overwritten: a
+	// top-level resource, data, module, variable or output block with the
+	// same labels as another one, a local value declared twice, a block
+	// whose labels run into another block, and an attribute and a block
+	// with the same name. Other repeated blocks, such as providers, which
+	// are told apart by their alias, or provisioners, are still collected
+	// into arrays.
+	Strict bool
+
+	// EmptyBlockMode controls the representation of empty blocks.
+	EmptyBlockMode EmptyBlockMode
+
+	// ContextValues substitutes references to context constants with the
+	// given strings, keyed by reference, e.g. "terraform.workspace",
+	// "path.module", "path.root" or "path.cwd".
+	ContextValues map[string]string
+
+	// StructuredReferences emits references such as var.region as
+	// {"$ref": ["var", "region"]} instead of "${var.region}". For and
+	// conditional expressions become {"$for": {...}} and
+	// {"$cond": ..., "$true": ..., "$false": ...}, with their parts
+	// converted the same way. References inside string templates are left
+	// as text.
+	StructuredReferences bool
+
+	// CaptureSource records the raw source text of every attribute
+	// expression in Artifacts.Sources.
+	CaptureSource bool
+
+	// CaptureRanges records the source range of every block and
+	// attribute in Artifacts.Ranges, keyed by JSON pointer.
+	CaptureRanges bool
+
+	// Report counts blocks, attributes, wrapped and simplified expressions
+	// and dropped comments into Artifacts.Report.
+	Report bool
+
+	// MaxResourcesPerFile, if positive, produces a warning when the file
+	// declares more resource blocks.
+	MaxResourcesPerFile int
+
+	// MaxAttributeValueSize, if positive, produces a warning for every
+	// attribute whose expression source is longer, in bytes.
+	MaxAttributeValueSize int
+
+	// MaxDepth, if positive, fails the conversion of input nesting blocks,
+	// expressions or brackets deeper, before it can exhaust the stack. Each
+	// block and each expression within another counts as one level.
+	MaxDepth int
+
+	// MaxExpansion bounds the elements evaluating the expressions of a file
+	// expands to, for Simplify, Variables and InlineLocals: every iteration
+	// of a for expression, and every element of the lists, sets, tuples,
+	// maps and objects functions return, counts as one. Past it,
+	// expressions expanding further are left unsimplified and a warning
+	// is reported. Zero stands for DefaultMaxExpansion and a negative value
+	// lifts the bound.
+	MaxExpansion int
+
+	// FormatNumber, if set, renders every number literal instead of the
+	// default cty JSON encoding. It must return valid JSON, e.g. a quoted
+	// string for 64-bit IDs.
+	FormatNumber func(cty.Value) (json.RawMessage, error)
+
+	// PreserveNumbers copies number literals from the source as
+	// json.Number, e.g. 1e400 or 1.50, instead of re-encoding their value.
+	// It takes precedence over FormatNumber and ValueEncoder for literals;
+	// evaluated numbers are still encoded.
+	PreserveNumbers bool
+
+	// Redact replaces the values of attributes, and of the keys and
+	// elements of their object and tuple values, whose path matches one of
+	// the patterns. A pattern is a dot-separated list of globs matched
+	// against the trailing path segments, e.g. "*.password" or "secret_*";
+	// SensitivePatterns holds common ones.
+	Redact []string
+
+	// RedactSensitive also redacts the default of every variable and the
+	// value of every output declared with sensitive = true.
+	RedactSensitive bool
+
+	// RedactSalt, if set, replaces redacted values with a salted hash of
+	// the value instead of a fixed placeholder, so that redacted documents
+	// remain diffable.
+	RedactSalt []byte
+
+	// OnDecision, if set, is called for every lossy heuristic the converter
+	// applies, such as wrapping an expression as "${...}".
+	OnDecision func(Decision)
+
+	// DecisionSampleRate reports only this fraction of decisions to
+	// OnDecision, chosen at random. Zero reports every decision.
+	DecisionSampleRate float64
+
+	// IncludeBlocks, if set, limits the top-level blocks converted to the
+	// ones matching one of its patterns, and ExcludeBlocks drops the ones
+	// matching one of its patterns. A pattern is a path in the form of
+	// Path.String whose segments are path.Match patterns, matched against
+	// the type and the leading labels of the block, e.g. resource.aws_*
+	// or provider.
+	IncludeBlocks []string
+	ExcludeBlocks []string
+
+	// Dialect, if set, validates the input against the dialect's rules,
+	// reporting violations as warnings, and emits its repeated blocks as
+	// arrays.
+	Dialect *Dialect
+
+	// AlwaysArrayBlocks emits every block as an array, even when only one
+	// block with the address exists, so consumers see the same shape
+	// regardless of how many blocks a file declares.
+	AlwaysArrayBlocks bool
+
+	// BlockShapes sets the shape of blocks by block type, e.g.
+	// {"locals": BlockShapeObject, "provider": BlockShapeArray}. It takes
+	// precedence over AlwaysArrayBlocks and the dialect.
+	BlockShapes map[string]BlockShape
+
+	// TerraformMode produces output that Terraform accepts as a .tf.json
+	// file: locals blocks are merged, provisioners become an array of
+	// single-key objects to keep their order, and the attributes Terraform
+	// reads as references or type constraints rather than expressions,
+	// such as depends_on or a variable's type, hold their bare source.
+	// It implies NoTemplateMarkers.
+	TerraformMode bool
+
+	// Simplify evaluates expressions that only depend on literals and the
+	// built-in functions, e.g. 1 + 2 or max(1, 2), instead of wrapping
+	// them as "${...}". Of a conditional whose condition resolves, only
+	// the chosen branch is converted.
+	Simplify bool
+
+	// TerraformFunctions lets Simplify evaluate calls to all of the pure
+	// built-in functions of Terraform, as listed by TerraformFunctions,
+	// e.g. cidrsubnet or lookup, instead of a common subset of them.
+	TerraformFunctions bool
+
+	// EvalContext widens what Simplify can resolve with the given
+	// variables and functions, in addition to the built-in functions.
+	EvalContext *hcl.EvalContext
+
+	// InlineLocals evaluates the locals of the top-level locals blocks that
+	// Simplify can resolve, given the other locals, and replaces references
+	// to them such as local.name with their values. The resolved locals are
+	// left out of the output; the others are kept, in a single locals
+	// object. It implies Simplify.
+	InlineLocals bool
+
+	// Variables, if set, are the values of var.*, e.g. loaded with
+	// LoadVarFile, so that references to them such as var.region resolve to
+	// their values instead of "${var.region}". It implies Simplify, and
+	// takes precedence over the var of EvalContext.
+	Variables map[string]cty.Value
+
+	// NoTemplateMarkers wraps expressions inside string templates as
+	// ${...} instead of @@@{...}@@@.
+	NoTemplateMarkers bool
+
+	// TemplateMarkerPrefix and TemplateMarkerSuffix replace the @@@{ and
+	// }@@@ markers, e.g. with "<<" and ">>" for a replace pipeline of
+	// one's own. They are used only if at least one is set, and markers
+	// other than the default ones are not unwrapped by JsonToNativeHcl.
+	TemplateMarkerPrefix string
+	TemplateMarkerSuffix string
+
+	// ValueEncoder, if set, overrides how literal and evaluated values are
+	// rendered, per value. FormatNumber still applies to the numbers it
+	// leaves to the default encoding.
+	ValueEncoder ValueEncoder
+
+	// BeforeAttribute and BeforeBlock, if set, are called with the path and
+	// the *hclsyntax.Attribute or *hclsyntax.Block of every attribute and
+	// block before it is converted. Returning false drops it; returning a
+	// non-nil value uses that value instead of converting it.
+	BeforeAttribute TransformFunc
+	BeforeBlock     TransformFunc
+	// AfterAttribute and AfterBlock, if set, are called with the path and
+	// the converted value of every attribute and block, the body of a block
+	// not yet placed under its labels, and return the value to store, or
+	// false to drop it. Keys are renamed by the hook of the enclosing block.
+	AfterAttribute TransformFunc
+	AfterBlock     TransformFunc
+
+	// ExpressionHandlers replace the strategy for kinds of expressions,
+	// keyed by their type, e.g.
+	// reflect.TypeOf((*hclsyntax.FunctionCallExpr)(nil)), like Handle
+	// does on a Converter. Handle takes precedence.
+	ExpressionHandlers map[reflect.Type]ExpressionFunc
+
+	// Logger, if set, receives the converter's debug messages. The
+	// converter is quiet by default.
+	Logger Logger
+
+	// Durations and Sizes, using the same patterns as Redact, select
+	// attributes whose string values such as "30s" or "512MiB" are emitted
+	// as {"seconds": 30} and as a number of bytes respectively.
+	Durations []string
+	Sizes     []string
+
+	// Defaults maps block patterns to attribute values to add to matching
+	// blocks that do not set them. A pattern is matched like one of Redact
+	// against the block type and labels, e.g. "aws_instance.*" or
+	// "lifecycle". The additions are listed in Artifacts.Injected.
+	Defaults map[string]map[string]interface{}
+
+	// PreserveOrder writes the keys of every object in the order they are
+	// declared in the source instead of sorted.
+	PreserveOrder bool
+
+	// Canonical emits canonical JSON: sorted keys, numbers without
+	// exponent or trailing zeros, and no trailing newline, so the same
+	// input always produces the same bytes. It overrides PreserveOrder.
+	Canonical bool
+
+	// CommentKey, if set, keeps comments: every object of a body with
+	// comments gets this key, e.g. "//", holding an object that maps
+	// attribute names to the text of the comments right above or after
+	// them. The comments of a block itself are under the empty name.
+	CommentKey string
+
+	// ExprMode selects the representation of expressions that are not
+	// literals. It takes precedence over StructuredReferences.
+	ExprMode ExprMode
+
+	// Encoding selects the format of the encoded document, JSON by
+	// default. The other encodings are derived from the JSON, so every
+	// option shaping it applies to them too.
+	Encoding Encoding
+
+	// Cache, if set, returns the artifacts of an earlier conversion of the
+	// same input, file name and options instead of converting again, e.g.
+	// a NewLRUCache. The artifacts it returns are shared and must not be
+	// modified. Conversions whose options hold functions, pointers or
+	// interfaces, e.g. hooks, an EvalContext or Variables, or that use
+	// expression strategies bypass the cache, which cannot tell them apart.
+	Cache Cache
+}
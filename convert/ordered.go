@@ -0,0 +1,92 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// recordKey notes that key was added to the object at the given path.
+func (c *Converter) recordKey(at Path, key string) {
+	if !c.opts.PreserveOrder {
+		return
+	}
+	pointer := at.Pointer()
+	c.keyOrder[pointer] = append(c.keyOrder[pointer], key)
+}
+
+// encodeOrdered encodes document like encodeJSON, writing the keys of every
+// object in the order recorded in keyOrder. Keys that were not recorded,
+// such as injected defaults, follow in sorted order.
+func encodeOrdered(document jsonObj, keyOrder map[string][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeOrdered(&buf, document, nil, keyOrder); err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "marshal json", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func writeOrdered(buf *bytes.Buffer, value interface{}, at Path, keyOrder map[string][]string) error {
+	switch v := value.(type) {
+	case jsonObj:
+		keys := make([]string, 0, len(v))
+		seen := make(map[string]bool, len(v))
+		for _, key := range keyOrder[at.Pointer()] {
+			if _, exists := v[key]; exists && !seen[key] {
+				keys = append(keys, key)
+				seen[key] = true
+			}
+		}
+		var rest []string
+		for key := range v {
+			if !seen[key] {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		keys = append(keys, rest...)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeValue(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeOrdered(buf, v[key], append(at[:len(at):len(at)], key), keyOrder); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeOrdered(buf, elem, append(at[:len(at):len(at)], strconv.Itoa(i)), keyOrder); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+	return writeValue(buf, value)
+}
+
+// writeValue writes a leaf value the same way encodeJSONStd does.
+func writeValue(buf *bytes.Buffer, value interface{}) error {
+	var leaf bytes.Buffer
+	encoder := json.NewEncoder(&leaf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(leaf.Bytes(), []byte("\n")))
+	return nil
+}
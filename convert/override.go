@@ -0,0 +1,211 @@
+package convert
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// overrideLabels is the number of labels of the top-level block types that
+// Terraform override files may override.
+var overrideLabels = map[string]int{
+	"resource":  2,
+	"data":      2,
+	"provider":  1,
+	"variable":  1,
+	"output":    1,
+	"module":    1,
+	"locals":    0,
+	"terraform": 0,
+}
+
+// ConvertAndMerge converts the files at paths and merges them into the
+// effective configuration of a Terraform module. The other files are merged
+// as by MergeDocuments; override files, override.tf and *_override.tf and
+// their .tf.json forms, are then applied in name order following
+// Terraform's rules:
+//
+//   - an attribute of an override block replaces the original one, and a
+//     nested block replaces all original nested blocks of its type;
+//   - lifecycle blocks of resources and the required_providers of the
+//     terraform block are merged argument by argument instead, and a
+//     backend or cloud block replaces either one;
+//   - local values are overridden one by one, and providers are matched by
+//     name and alias.
+//
+// Every override block needs an original block to override.
+func ConvertAndMerge(paths ...string) (map[string]interface{}, error) {
+	documents := make(map[string]jsonObj)
+	var overrides []string
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		artifacts, err := ConvertAll(src, path, Options{})
+		if err != nil {
+			return nil, fmt.Errorf("convert %s: %w", path, err)
+		}
+
+		documents[path] = artifacts.Document
+		if isOverrideFile(path) {
+			overrides = append(overrides, path)
+		}
+	}
+
+	primary := make(map[string]jsonObj, len(documents)-len(overrides))
+	for path, document := range documents {
+		if !isOverrideFile(path) {
+			primary[path] = document
+		}
+	}
+	merged, err := MergeDocuments(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		return filepath.Base(overrides[i]) < filepath.Base(overrides[j])
+	})
+	for _, path := range overrides {
+		if err := applyOverride(merged, documents[path]); err != nil {
+			return nil, fmt.Errorf("override %s: %w", path, err)
+		}
+	}
+	return merged, nil
+}
+
+func isOverrideFile(path string) bool {
+	name := strings.TrimSuffix(filepath.Base(path), ".json")
+	if !strings.HasSuffix(name, ".tf") {
+		return false
+	}
+	name = strings.TrimSuffix(name, ".tf")
+	return name == "override" || strings.HasSuffix(name, "_override")
+}
+
+func applyOverride(merged, override jsonObj) error {
+	blockTypes := make([]string, 0, len(override))
+	for blockType := range override {
+		blockTypes = append(blockTypes, blockType)
+	}
+	sort.Strings(blockTypes)
+
+	for _, blockType := range blockTypes {
+		labels, ok := overrideLabels[blockType]
+		if !ok {
+			return newError(CodeMissingOverrideBase, nil, fmt.Sprintf("%s cannot be overridden", blockType), nil)
+		}
+		if err := overrideLevel(merged, override, Path{blockType}, labels, blockType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overrideLevel walks the labels below at, labels levels deep, and overrides
+// the blocks found there.
+func overrideLevel(merged, override jsonObj, at Path, labels int, blockType string) error {
+	key := at[len(at)-1]
+	if _, exists := merged[key]; !exists {
+		return newError(CodeMissingOverrideBase, nil, fmt.Sprintf("%s has no original definition", at), nil)
+	}
+	if labels > 0 {
+		from, ok := override[key].(jsonObj)
+		to, isObject := merged[key].(jsonObj)
+		if !ok || !isObject {
+			return newError(CodeMissingOverrideBase, nil, fmt.Sprintf("%s has no original definition", at), nil)
+		}
+		names := make([]string, 0, len(from))
+		for name := range from {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := overrideLevel(to, from, append(at[:len(at):len(at)], name), labels-1, blockType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, block := range blockList(override[key]) {
+		if err := overrideBlock(merged, key, block, at, blockType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overrideBlock applies the body of one override block to the original
+// block stored at merged[key], an object or an array of repeated blocks.
+func overrideBlock(merged jsonObj, key string, block jsonObj, at Path, blockType string) error {
+	originals := blockList(merged[key])
+	if blockType == "locals" {
+		for name, value := range block {
+			original := findBlock(originals, func(b jsonObj) bool { _, ok := b[name]; return ok })
+			if original == nil {
+				return newError(CodeMissingOverrideBase, nil, fmt.Sprintf("local.%s has no original definition", name), nil)
+			}
+			original[name] = value
+		}
+		return nil
+	}
+
+	original := findBlock(originals, func(b jsonObj) bool {
+		// providers of the same name are told apart by their alias
+		return blockType != "provider" || fmt.Sprint(b["alias"]) == fmt.Sprint(block["alias"])
+	})
+	if original == nil {
+		return newError(CodeMissingOverrideBase, nil, fmt.Sprintf("%s has no original definition", at), nil)
+	}
+
+	for name, value := range block {
+		nested, isBlock := value.(jsonObj)
+		current, hasCurrent := original[name].(jsonObj)
+		mergeArguments := (name == "lifecycle" && (blockType == "resource" || blockType == "data")) ||
+			(name == "required_providers" && blockType == "terraform")
+		if mergeArguments && isBlock && hasCurrent {
+			for argument, v := range nested {
+				current[argument] = v
+			}
+			continue
+		}
+		if blockType == "terraform" && (name == "backend" || name == "cloud") {
+			// either one replaces the backend of the original
+			delete(original, "backend")
+			delete(original, "cloud")
+		}
+		original[name] = value
+	}
+	return nil
+}
+
+// blockList returns the blocks stored as value, a single object or an array
+// of them.
+func blockList(value interface{}) []jsonObj {
+	switch v := value.(type) {
+	case jsonObj:
+		return []jsonObj{v}
+	case []interface{}:
+		blocks := make([]jsonObj, 0, len(v))
+		for _, item := range v {
+			if block, ok := item.(jsonObj); ok {
+				blocks = append(blocks, block)
+			}
+		}
+		return blocks
+	}
+	return nil
+}
+
+func findBlock(blocks []jsonObj, match func(jsonObj) bool) jsonObj {
+	for _, block := range blocks {
+		if match(block) {
+			return block
+		}
+	}
+	return nil
+}
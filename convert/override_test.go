@@ -0,0 +1,132 @@
+package convert
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertAndMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+		code  Code
+	}{
+		{
+			name: "attributes",
+			files: map[string]string{
+				"main.tf": `resource "aws_instance" "web" {
+  ami           = "a"
+  instance_type = "t2.micro"
+}`,
+				"override.tf": `resource "aws_instance" "web" {
+  ami = "b"
+}`,
+			},
+			want: `{"resource":{"aws_instance":{"web":{"ami":"b","instance_type":"t2.micro"}}}}`,
+		},
+		{
+			name: "nested blocks",
+			files: map[string]string{
+				"main.tf": `resource "aws_instance" "web" {
+  ebs_block_device {
+    device_name = "a"
+  }
+  ebs_block_device {
+    device_name = "b"
+  }
+  lifecycle {
+    create_before_destroy = true
+  }
+}`,
+				"web_override.tf": `resource "aws_instance" "web" {
+  ebs_block_device {
+    device_name = "c"
+  }
+  lifecycle {
+    prevent_destroy = true
+  }
+}`,
+			},
+			want: `{"resource":{"aws_instance":{"web":{"ebs_block_device":{"device_name":"c"},"lifecycle":{"create_before_destroy":true,"prevent_destroy":true}}}}}`,
+		},
+		{
+			name: "override files in name order",
+			files: map[string]string{
+				"main.tf":          `variable "a" { default = 1 }`,
+				"b_override.tf":    `variable "a" { default = 3 }`,
+				"a_override.tf":    `variable "a" { default = 2 }`,
+				"c.tf":             `locals { x = 1 }`,
+				"override.tf.json": `{"locals": {"x": 2}}`,
+			},
+			want: `{"locals":{"x":2},"variable":{"a":{"default":3}}}`,
+		},
+		{
+			name: "providers by alias",
+			files: map[string]string{
+				"main.tf": `provider "aws" { region = "a" }
+provider "aws" {
+  alias  = "b"
+  region = "b"
+}`,
+				"override.tf": `provider "aws" {
+  alias  = "b"
+  region = "c"
+}`,
+			},
+			want: `{"provider":{"aws":[{"region":"a"},{"alias":"b","region":"c"}]}}`,
+		},
+		{
+			name: "backend",
+			files: map[string]string{
+				"main.tf": `terraform {
+  backend "s3" {}
+}`,
+				"override.tf": `terraform {
+  cloud {}
+}`,
+			},
+			want: `{"terraform":{"cloud":{}}}`,
+		},
+		{
+			name: "missing original",
+			files: map[string]string{
+				"main.tf":     `resource "aws_instance" "web" {}`,
+				"override.tf": `resource "aws_instance" "db" {}`,
+			},
+			code: CodeMissingOverrideBase,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var paths []string
+			for name, src := range test.files {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				paths = append(paths, path)
+			}
+			merged, err := ConvertAndMerge(paths...)
+			if test.code != "" {
+				if CodeOf(err) != test.code {
+					t.Fatalf("got %v, want %s", err, test.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(merged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
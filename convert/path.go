@@ -0,0 +1,282 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// Path addresses a value in a converted document with one segment per
+// object key or array index. Block types and labels are segments of their
+// own, so labels containing dots, slashes or quotes stay unambiguous.
+type Path []string
+
+// Pointer renders p as an RFC 6901 JSON Pointer, escaping "~" and "/".
+func (p Path) Pointer() string {
+	var builder strings.Builder
+	for _, segment := range p {
+		builder.WriteByte('/')
+		builder.WriteString(pointerEscaper.Replace(segment))
+	}
+	return builder.String()
+}
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// ParsePointer parses an RFC 6901 JSON Pointer into a Path.
+func ParsePointer(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("pointer %q does not start with /", s), nil)
+	}
+
+	segments := strings.Split(s[1:], "/")
+	for i, segment := range segments {
+		segments[i] = pointerUnescaper.Replace(segment)
+	}
+	return Path(segments), nil
+}
+
+// String renders p in dotted form, e.g. resource.aws_instance.web. Segments
+// that are not plain identifiers are written in brackets, quoted if they are
+// not array indexes: resource["a.b/c"][0].
+func (p Path) String() string {
+	var builder strings.Builder
+	for i, segment := range p {
+		switch {
+		case isIdentifier(segment):
+			if i > 0 {
+				builder.WriteByte('.')
+			}
+			builder.WriteString(segment)
+		case isIndex(segment):
+			builder.WriteString("[" + segment + "]")
+		default:
+			builder.WriteString("[" + strconv.Quote(segment) + "]")
+		}
+	}
+	return builder.String()
+}
+
+// ParsePath parses the dotted form produced by Path.String.
+func ParsePath(s string) (Path, error) {
+	path := Path{}
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '.':
+			if i == 0 || i == len(s)-1 || s[i+1] == '.' || s[i+1] == '[' {
+				return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("unexpected '.' at offset %d of %q", i, s), nil)
+			}
+			i++
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if i+1 < len(s) && s[i+1] == '"' {
+				end = closingQuote(s, i+1)
+				if end < 0 || end+1 >= len(s) || s[end+1] != ']' {
+					return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("unterminated key at offset %d of %q", i, s), nil)
+				}
+				segment, err := strconv.Unquote(s[i+1 : end+1])
+				if err != nil {
+					return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("invalid key at offset %d of %q", i, s), err)
+				}
+				path = append(path, segment)
+				i = end + 2
+				continue
+			}
+			if end < 0 || !isIndex(s[i+1:i+end]) {
+				return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("invalid index at offset %d of %q", i, s), nil)
+			}
+			path = append(path, s[i+1:i+end])
+			i += end + 1
+		default:
+			end := strings.IndexAny(s[i:], ".[")
+			if end < 0 {
+				end = len(s) - i
+			}
+			path = append(path, s[i:i+end])
+			i += end
+		}
+	}
+	return path, nil
+}
+
+// closingQuote returns the offset of the quote closing the string literal
+// that starts at s[start], or -1.
+func closingQuote(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// enterPath appends segments to the path of the value being converted and
+// returns a function restoring the previous path.
+func (c *Converter) enterPath(segments ...string) func() {
+	saved := c.path
+	c.path = append(c.path[:len(c.path):len(c.path)], segments...)
+	return func() {
+		c.path = saved
+	}
+}
+
+// currentPath returns a copy of the path of the value being converted.
+func (c *Converter) currentPath() Path {
+	return append(Path{}, c.path...)
+}
+
+// hasPrefix reports whether p starts with all segments of prefix.
+func (p Path) hasPrefix(prefix Path) bool {
+	if len(p) < len(prefix) {
+		return false
+	}
+	for i, segment := range prefix {
+		if p[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// blockIndex returns the array index the next block with the given address
+// will get in out, or -1 if it will be stored as a single object. With
+// array set, the first block gets index 0 instead.
+func blockIndex(out jsonObj, address []string, array bool) int {
+	first := -1
+	if array {
+		first = 0
+	}
+
+	for _, key := range address[:len(address)-1] {
+		next, ok := out[key].(jsonObj)
+		if !ok {
+			return first
+		}
+		out = next
+	}
+
+	current, exists := out[address[len(address)-1]]
+	if !exists {
+		return first
+	}
+	switch v := current.(type) {
+	case []interface{}:
+		return len(v)
+	case jsonObj, nil:
+		return 1
+	}
+	return -1
+}
+
+// rebasePath moves everything recorded under prefix to prefix/0, once the
+// block at prefix became the first element of an array. Records under
+// prefix/1 belong to the block that caused the promotion and are kept;
+// the first block cannot have a child named "1" since attribute names and
+// block types are identifiers.
+func (c *Converter) rebasePath(prefix Path) {
+	second := append(prefix[:len(prefix):len(prefix)], "1")
+	rebase := func(p Path) Path {
+		rebased := append(append(Path{}, p[:len(prefix)]...), "0")
+		return append(rebased, p[len(prefix):]...)
+	}
+
+	for i, item := range c.skipped {
+		if item.Address.hasPrefix(prefix) && !item.Address.hasPrefix(second) {
+			c.skipped[i].Address = rebase(item.Address)
+		}
+	}
+
+	pointer := prefix.Pointer()
+	secondPointer := second.Pointer()
+	rebasePointer := func(key string) (string, bool) {
+		if key == secondPointer || strings.HasPrefix(key, secondPointer+"/") {
+			return "", false
+		}
+		if key == pointer || strings.HasPrefix(key, pointer+"/") {
+			return pointer + "/0" + key[len(pointer):], true
+		}
+		return "", false
+	}
+
+	sources := make(map[string]string)
+	for key, source := range c.sources {
+		if rebased, ok := rebasePointer(key); ok {
+			sources[rebased] = source
+			delete(c.sources, key)
+		}
+	}
+	for key, source := range sources {
+		c.sources[key] = source
+	}
+
+	injected := make(map[string]bool)
+	for key := range c.injected {
+		if rebased, ok := rebasePointer(key); ok {
+			injected[rebased] = true
+			delete(c.injected, key)
+		}
+	}
+	for key := range injected {
+		c.injected[key] = true
+	}
+
+	ranges := make(map[string]hcl.Range)
+	for key, r := range c.ranges {
+		if rebased, ok := rebasePointer(key); ok {
+			ranges[rebased] = r
+			delete(c.ranges, key)
+		}
+	}
+	for key, r := range ranges {
+		c.ranges[key] = r
+	}
+
+	keyOrder := make(map[string][]string)
+	for key, keys := range c.keyOrder {
+		if rebased, ok := rebasePointer(key); ok {
+			keyOrder[rebased] = keys
+			delete(c.keyOrder, key)
+		}
+	}
+	for key, keys := range keyOrder {
+		c.keyOrder[key] = keys
+	}
+}
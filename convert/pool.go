@@ -0,0 +1,44 @@
+package convert
+
+import (
+	"sync"
+)
+
+// ConverterPool reuses Converters sharing the same options, so servers
+// converting many small documents do not set up a Converter, its
+// evaluation context included, for every one of them.
+type ConverterPool struct {
+	pool sync.Pool
+}
+
+// NewConverterPool returns a pool of Converters using opts.
+func NewConverterPool(opts Options) *ConverterPool {
+	p := &ConverterPool{}
+	p.pool.New = func() interface{} {
+		return NewConverter(opts)
+	}
+	return p
+}
+
+// Get returns a Converter of the pool, or a new one. Strategies set with
+// Handle stay with the Converter once it is put back.
+func (p *ConverterPool) Get() *Converter {
+	return p.pool.Get().(*Converter)
+}
+
+// Put returns c to the pool. c must not be used afterwards.
+func (p *ConverterPool) Put(c *Converter) {
+	// drop what the last conversion referenced; the next one resets the rest
+	c.bytes, c.parseDiags, c.comments, c.ctx = nil, nil, nil, nil
+	c.skipped, c.warnings, c.errors = nil, nil, nil
+	c.sources, c.injected, c.ranges, c.keyOrder = nil, nil, nil, nil
+	p.pool.Put(c)
+}
+
+// ConvertAll converts the input with a Converter of the pool, like the
+// package-level ConvertAll.
+func (p *ConverterPool) ConvertAll(bytes []byte, filename string) (*Artifacts, error) {
+	c := p.Get()
+	defer p.Put(c)
+	return c.ConvertAll(bytes, filename)
+}
@@ -0,0 +1,48 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Query converts hclBytes and returns the value at path, given in the form
+// of Path.String, e.g. resource.aws_instance.web.ami. The value is a plain
+// Go value as returned by ToMap; repeated blocks are indexed like arrays,
+// e.g. resource.aws_instance.web.ebs[0].
+func Query(hclBytes []byte, path string) (interface{}, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	document, err := ToMap(hclBytes, "<input>")
+	if err != nil {
+		return nil, err
+	}
+	return lookupPath(document, p)
+}
+
+func lookupPath(value interface{}, p Path) (interface{}, error) {
+	for i, segment := range p {
+		at := p[:i].String()
+		if at == "" {
+			at = "the document"
+		}
+		switch v := value.(type) {
+		case jsonObj:
+			next, ok := v[segment]
+			if !ok {
+				return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("%s has no key %q", at, segment), nil)
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("%s has no index %s, only %d elements", at, segment, len(v)), nil)
+			}
+			value = v[index]
+		default:
+			return nil, newError(CodeInvalidPath, nil, fmt.Sprintf("%s is not an object or array", at), nil)
+		}
+	}
+	return value, nil
+}
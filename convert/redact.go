@@ -0,0 +1,140 @@
+package convert
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// redactedValue replaces redacted attribute values when no hash salt is set.
+const redactedValue = "(redacted)"
+
+// SensitivePatterns are Redact patterns for attribute and object key names
+// that commonly hold credentials, at any depth of a value.
+var SensitivePatterns = []string{"*password*", "*secret*", "*token*", "*private_key*", "*access_key*"}
+
+// sensitiveValues are the attributes holding the value of a variable or
+// output declared with sensitive = true.
+var sensitiveValues = map[string]string{
+	"variable": "default",
+	"output":   "value",
+}
+
+// redacted reports whether the attribute at the current path matches one of
+// Options.Redact, or holds the value of a sensitive variable or output
+// with Options.RedactSensitive.
+func (c *Converter) redacted() bool {
+	if c.sensitive && len(c.blockTypes) == 1 && sensitiveValues[c.blockTypes[0]] == c.path[len(c.path)-1] {
+		return true
+	}
+	return c.pathMatches(c.opts.Redact)
+}
+
+// isSensitiveBlock reports whether block is a top-level variable or output
+// declared with sensitive = true, if Options.RedactSensitive is set.
+func (c *Converter) isSensitiveBlock(block *hclsyntax.Block) bool {
+	if !c.opts.RedactSensitive || len(c.blockTypes) > 0 || sensitiveValues[block.Type] == "" {
+		return false
+	}
+	attr, ok := block.Body.Attributes["sensitive"]
+	if !ok {
+		return false
+	}
+	val, diags := attr.Expr.Value(nil)
+	return !diags.HasErrors() && val.Type() == cty.Bool && val.IsKnown() && !val.IsNull() && val.True()
+}
+
+// pathMatches reports whether the current path matches one of patterns.
+func (c *Converter) pathMatches(patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchPathSuffix(pattern, c.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathSuffix matches the dot-separated glob pattern against the last
+// segments of p, one segment per glob, so "*.password" matches
+// resource.aws_db_instance.main.password.
+func matchPathSuffix(pattern string, p Path) bool {
+	globs := strings.Split(pattern, ".")
+	if len(globs) > len(p) {
+		return false
+	}
+	tail := p[len(p)-len(globs):]
+	for i, glob := range globs {
+		if ok, err := path.Match(glob, tail[i]); !ok || err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// redactNested redacts the values of the objects and tuples under value,
+// the value of the attribute at the current path, whose path matches one of
+// Options.Redact, and reports whether it redacted any. value is changed in
+// place.
+func (c *Converter) redactNested(value interface{}) (interface{}, bool, error) {
+	if len(c.opts.Redact) == 0 {
+		return value, false, nil
+	}
+
+	redacted := false
+	switch v := value.(type) {
+	case jsonObj:
+		for key, elem := range v {
+			elem, nested, err := c.redactElem(key, elem)
+			if err != nil {
+				return nil, false, err
+			}
+			v[key] = elem
+			redacted = redacted || nested
+		}
+	case []interface{}:
+		for i, elem := range v {
+			elem, nested, err := c.redactElem(strconv.Itoa(i), elem)
+			if err != nil {
+				return nil, false, err
+			}
+			v[i] = elem
+			redacted = redacted || nested
+		}
+	}
+	return value, redacted, nil
+}
+
+// redactElem redacts elem, at key under the current path, if its path
+// matches one of Options.Redact, or else the values under it.
+func (c *Converter) redactElem(key string, elem interface{}) (interface{}, bool, error) {
+	leave := c.enterPath(key)
+	defer leave()
+	if c.pathMatches(c.opts.Redact) {
+		redacted, err := c.redact(elem)
+		return redacted, true, err
+	}
+	return c.redactNested(elem)
+}
+
+// redact returns the replacement for a converted value. With a salt the
+// value is replaced by an HMAC of its JSON encoding, so equal values still
+// compare equal across documents redacted with the same salt.
+func (c *Converter) redact(value interface{}) (interface{}, error) {
+	if len(c.opts.RedactSalt) == 0 {
+		return redactedValue, nil
+	}
+
+	encoded, err := encodeJSON(value)
+	if err != nil {
+		return nil, newError(CodeEncodeFailed, nil, "redact "+c.path.String(), err)
+	}
+	mac := hmac.New(sha256.New, c.opts.RedactSalt)
+	mac.Write(encoded)
+	return "sha256:" + hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,173 @@
+package convert
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRedactJSONSyntax(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		src  string
+		want string
+	}{
+		{
+			name: "pattern",
+			opts: Options{Redact: []string{"*password*"}},
+			src:  `{"resource": {"aws_db_instance": {"main": {"engine": "mysql", "password": "hunter2"}}}}`,
+			want: `{"resource":{"aws_db_instance":{"main":{"engine":"mysql","password":"(redacted)"}}}}`,
+		},
+		{
+			name: "labels are not attributes",
+			opts: Options{Redact: []string{"password"}},
+			src:  `{"variable": {"password": {"type": "string"}}}`,
+			want: `{"variable":{"password":{"type":"string"}}}`,
+		},
+		{
+			name: "repeated blocks",
+			opts: Options{Redact: []string{"resource.*.*.*.token"}},
+			src:  `{"resource": {"a": {"b": [{"token": "x"}, {"token": "y", "name": "z"}]}}}`,
+			want: `{"resource":{"a":{"b":[{"token":"(redacted)"},{"name":"z","token":"(redacted)"}]}}}`,
+		},
+		{
+			name: "nested",
+			opts: Options{Redact: []string{"*secret*"}},
+			src:  `{"provider": {"vault": {"auth": [{"client_secret": "x"}]}}, "client_secret": 1}`,
+			want: `{"client_secret":"(redacted)","provider":{"vault":{"auth":[{"client_secret":"(redacted)"}]}}}`,
+		},
+		{
+			name: "sensitive",
+			opts: Options{RedactSensitive: true},
+			src:  `{"variable": {"a": {"default": "x", "sensitive": true}, "b": {"default": "y"}}, "output": {"c": {"value": 1, "sensitive": true}}}`,
+			want: `{"output":{"c":{"sensitive":true,"value":"(redacted)"}},"variable":{"a":{"default":"(redacted)","sensitive":true},"b":{"default":"y"}}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			artifacts, err := ConvertAll([]byte(test.src), "main.tf.json", test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(artifacts.Document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestRedactSaltJSONSyntax checks that a value hashes the same in both
+// syntaxes.
+func TestRedactSaltJSONSyntax(t *testing.T) {
+	opts := Options{Redact: []string{"password"}, RedactSalt: []byte("salt")}
+	native, err := ConvertAll([]byte(`password = "hunter2"`), "main.tf", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonSyntax, err := ConvertAll([]byte(`{"password": "hunter2"}`), "main.tf.json", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := jsonSyntax.Document["password"], native.Document["password"]; got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if native.Document["password"] == "hunter2" {
+		t.Error("password was not redacted")
+	}
+}
+
+func TestRedactNestedValues(t *testing.T) {
+	src := `provider "db" {
+  config = { password = "hunter2", host = "db" }
+  list   = [{ password = "p3" }, { name = "n" }]
+  plain  = { host = "db" }
+}
+`
+	tests := []struct {
+		name    string
+		opts    Options
+		want    string
+		dropped []string
+	}{
+		{
+			name:    "patterns",
+			opts:    Options{Redact: SensitivePatterns, CaptureSource: true},
+			want:    `{"provider":{"db":{"config":{"host":"db","password":"(redacted)"},"list":[{"password":"(redacted)"},{"name":"n"}],"plain":{"host":"db"}}}}`,
+			dropped: []string{"/provider/db/config", "/provider/db/list"},
+		},
+		{
+			name:    "simplified",
+			opts:    Options{Redact: SensitivePatterns, CaptureSource: true, Simplify: true},
+			want:    `{"provider":{"db":{"config":{"host":"db","password":"(redacted)"},"list":[{"password":"(redacted)"},{"name":"n"}],"plain":{"host":"db"}}}}`,
+			dropped: []string{"/provider/db/config", "/provider/db/list"},
+		},
+		{
+			name:    "element path",
+			opts:    Options{Redact: []string{"list.0"}, CaptureSource: true},
+			want:    `{"provider":{"db":{"config":{"host":"db","password":"hunter2"},"list":["(redacted)",{"name":"n"}],"plain":{"host":"db"}}}}`,
+			dropped: []string{"/provider/db/list"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			artifacts, err := ConvertAll([]byte(src), "main.tf", test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(artifacts.Document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+			for _, pointer := range test.dropped {
+				if source, ok := artifacts.Sources[pointer]; ok {
+					t.Errorf("got source %s of %s, want it left out", source, pointer)
+				}
+			}
+			if _, ok := artifacts.Sources["/provider/db/plain"]; !ok {
+				t.Errorf("the source of plain is missing: %v", artifacts.Sources)
+			}
+		})
+	}
+}
+
+// TestSensitivePatternsNested checks that the preset catches credentials
+// nested in object and tuple values, in both syntaxes.
+func TestSensitivePatternsNested(t *testing.T) {
+	want := `{"resource":{"aws_instance":{"web":{"tags":[{"secret_name":"(redacted)"}],"user_data":{"api_token":"(redacted)","db":{"admin_password":"(redacted)","host":"db"}}}}}}`
+	tests := []struct {
+		filename string
+		src      string
+	}{
+		{"main.tf", `resource "aws_instance" "web" {
+  user_data = { db = { admin_password = "a", host = "db" }, api_token = "b" }
+  tags      = [{ secret_name = "c" }]
+}
+`},
+		{"main.tf.json", `{"resource": {"aws_instance": {"web": {
+  "user_data": {"db": {"admin_password": "a", "host": "db"}, "api_token": "b"},
+  "tags": [{"secret_name": "c"}]
+}}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.filename, func(t *testing.T) {
+			artifacts, err := ConvertAll([]byte(test.src), test.filename, Options{Redact: SensitivePatterns})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(artifacts.Document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
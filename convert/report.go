@@ -0,0 +1,82 @@
+package convert
+
+import (
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Report summarizes a conversion, so that the fidelity of conversions can
+// be tracked over time. The counts cover native syntax input; of JSON
+// syntax input, only the sizes and the simplified expressions are reported.
+type Report struct {
+	// Blocks counts the converted blocks by type, nested ones included.
+	Blocks map[string]int `json:"blocks"`
+	// Attributes counts the converted attributes, nested ones included.
+	Attributes int `json:"attributes"`
+	// WrappedExpressions counts the expressions emitted as their source
+	// text, as "${...}" or between template markers.
+	WrappedExpressions int `json:"wrapped_expressions"`
+	// SimplifiedExpressions counts the expressions resolved by
+	// Options.Simplify, and the conditionals of which only one branch was
+	// converted.
+	SimplifiedExpressions int `json:"simplified_expressions"`
+	// CommentsDropped counts the comments of the input not kept under
+	// Options.CommentKey.
+	CommentsDropped int `json:"comments_dropped"`
+	// InputBytes and OutputBytes are the sizes of the input and of the
+	// encoded document.
+	InputBytes  int `json:"input_bytes"`
+	OutputBytes int `json:"output_bytes"`
+}
+
+func (c *Converter) countBlock(block *hclsyntax.Block) {
+	if c.report != nil {
+		c.report.Blocks[block.Type]++
+	}
+}
+
+func (c *Converter) countAttribute() {
+	if c.report != nil {
+		c.report.Attributes++
+	}
+}
+
+func (c *Converter) countWrapped() {
+	if c.report != nil {
+		c.report.WrappedExpressions++
+	}
+}
+
+func (c *Converter) countSimplified() {
+	if c.report != nil {
+		c.report.SimplifiedExpressions++
+	}
+}
+
+// countKeptComment marks the comment at index i of c.comments as kept.
+func (c *Converter) countKeptComment(i int) {
+	if c.report != nil {
+		c.keptComments[i] = true
+	}
+}
+
+// finishReport completes the report of the conversion of file.
+func (c *Converter) finishReport(file *hcl.File) *Report {
+	report := c.report
+	report.InputBytes = len(file.Bytes)
+	if _, ok := file.Body.(*hclsyntax.Body); !ok {
+		return report
+	}
+
+	if c.opts.CommentKey != "" {
+		report.CommentsDropped = len(c.comments) - len(c.keptComments)
+		return report
+	}
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, "", hcl.Pos{Line: 1, Column: 1})
+	for _, token := range tokens {
+		if token.Type == hclsyntax.TokenComment {
+			report.CommentsDropped++
+		}
+	}
+	return report
+}
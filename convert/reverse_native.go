@@ -0,0 +1,385 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// nativeBlock describes how the objects of a block type are printed back as
+// native blocks: the number of labels, the nested block types, and whether
+// arrays of objects in its body of keys not listed there are printed as
+// repeated blocks too, as for the ingress blocks of a resource.
+type nativeBlock struct {
+	labels   int
+	blocks   map[string]nativeBlock
+	repeated bool
+}
+
+var (
+	nativeConditions = map[string]nativeBlock{
+		"precondition":  {},
+		"postcondition": {},
+	}
+	nativeResourceBlocks = map[string]nativeBlock{
+		"lifecycle":     {blocks: nativeConditions},
+		"connection":    {},
+		"provisioner":   {labels: 1, blocks: map[string]nativeBlock{"connection": {}}, repeated: true},
+		"dynamic":       {labels: 1, blocks: map[string]nativeBlock{"content": {repeated: true}}},
+		"timeouts":      {},
+		"precondition":  {},
+		"postcondition": {},
+	}
+
+	// nativeRoot is the schema of the top-level body; keys it does not
+	// list are printed as attributes.
+	nativeRoot = nativeBlock{blocks: map[string]nativeBlock{
+		"resource": {labels: 2, blocks: nativeResourceBlocks, repeated: true},
+		"data":     {labels: 2, blocks: nativeResourceBlocks, repeated: true},
+		"provider": {labels: 1, repeated: true},
+		"module":   {labels: 1},
+		"variable": {labels: 1, blocks: map[string]nativeBlock{"validation": {}}},
+		"output":   {labels: 1, blocks: map[string]nativeBlock{"precondition": {}}},
+		"check":    {labels: 1, blocks: map[string]nativeBlock{"assert": {}, "data": {labels: 2, repeated: true}}},
+		"locals":   {},
+		"moved":    {},
+		"import":   {},
+		"removed":  {blocks: map[string]nativeBlock{"lifecycle": {}, "provisioner": {labels: 1, repeated: true}}},
+		"terraform": {blocks: map[string]nativeBlock{
+			"required_providers": {},
+			"backend":            {labels: 1},
+			"cloud":              {blocks: map[string]nativeBlock{"workspaces": {}}},
+			"provider_meta":      {labels: 1},
+		}},
+	}}
+)
+
+// JsonToNativeHcl converts JSON produced by HclToJson back to HCL. Unlike
+// JsonToHcl it reports failures, and it unwraps "${...}" strings and
+// @@@{...}@@@ markers back into native expressions. The objects of the
+// Terraform block types are printed as blocks with their labels; other
+// top-level keys, as attributes.
+func JsonToNativeHcl(input []byte) ([]byte, error) {
+	if !json.Valid(input) {
+		return nil, newError(CodeInvalidJSON, nil, "unable to parse JSON", nil)
+	}
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, newError(CodeInvalidJSON, nil, "unable to parse JSON", err)
+	}
+	root, ok := document.(jsonObj)
+	if !ok {
+		return nil, newError(CodeInvalidJSON, nil, "the document is not an object", nil)
+	}
+
+	file := hclwrite.NewEmptyFile()
+	if err := writeNativeBody(file.Body(), root, nativeRoot, true); err != nil {
+		return nil, err
+	}
+	out := hclwrite.Format(file.Bytes())
+	// the printed expressions come from the input, so make sure they did
+	// not change the structure of the output
+	if _, diags := hclsyntax.ParseConfig(out, "", hcl.Pos{Line: 1, Column: 1}); diags.HasErrors() {
+		return nil, newError(CodeEncodeFailed, nil, "Unable to print HCL", diags)
+	}
+	return out, nil
+}
+
+// writeNativeBody prints obj into body, attributes first and then blocks,
+// each in key order. Top-level blocks are separated by blank lines.
+func writeNativeBody(body *hclwrite.Body, obj jsonObj, schema nativeBlock, top bool) error {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var blocks []string
+	for _, key := range keys {
+		if _, ok := nativeBlockOf(schema, key, obj[key]); ok {
+			blocks = append(blocks, key)
+			continue
+		}
+		if !hclsyntax.ValidIdentifier(key) {
+			return newError(CodeInvalidValue, nil, fmt.Sprintf("attribute name %q is not an identifier", key), nil)
+		}
+		tokens, err := nativeValueTokens(obj[key])
+		if err != nil {
+			return err
+		}
+		body.SetAttributeRaw(key, tokens)
+	}
+
+	for i, key := range blocks {
+		if top && (i > 0 || len(blocks) < len(keys)) {
+			body.AppendNewline()
+		}
+		nested, _ := nativeBlockOf(schema, key, obj[key])
+		if err := writeNativeBlocks(body, key, nil, obj[key], nested, top); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nativeBlockOf returns the schema of the blocks key holds in a body of the
+// given schema, and whether value fits it.
+func nativeBlockOf(schema nativeBlock, key string, value interface{}) (nativeBlock, bool) {
+	if nested, ok := schema.blocks[key]; ok {
+		return nested, fitsNativeBlock(value, nested.labels)
+	}
+	if !schema.repeated || !hclsyntax.ValidIdentifier(key) {
+		return nativeBlock{}, false
+	}
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return nativeBlock{}, false
+	}
+	for _, elem := range list {
+		if _, ok := elem.(jsonObj); !ok {
+			return nativeBlock{}, false
+		}
+	}
+	return nativeBlock{repeated: true}, true
+}
+
+// fitsNativeBlock reports whether value can be printed as blocks with the
+// given number of labels: an object per label level, down to the bodies,
+// where a non-empty array at any level stands for several blocks and a null
+// body for an empty one.
+func fitsNativeBlock(value interface{}, labels int) bool {
+	switch v := value.(type) {
+	case nil:
+		return labels == 0
+	case jsonObj:
+		if labels == 0 {
+			return true
+		}
+		for _, elem := range v {
+			if !fitsNativeBlock(elem, labels-1) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		if len(v) == 0 {
+			return false
+		}
+		for _, elem := range v {
+			if elem == nil || !fitsNativeBlock(elem, labels) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// writeNativeBlocks prints the blocks of value, which fits schema, with
+// the labels collected so far.
+func writeNativeBlocks(body *hclwrite.Body, typeName string, labels []string, value interface{}, schema nativeBlock, top bool) error {
+	if list, ok := value.([]interface{}); ok {
+		for i, elem := range list {
+			if top && i > 0 {
+				body.AppendNewline()
+			}
+			if err := writeNativeBlocks(body, typeName, labels, elem, schema, top); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	obj, _ := value.(jsonObj)
+	if len(labels) < schema.labels {
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if top && i > 0 {
+				body.AppendNewline()
+			}
+			if err := writeNativeBlocks(body, typeName, append(labels[:len(labels):len(labels)], key), obj[key], schema, top); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	block := body.AppendNewBlock(typeName, labels)
+	return writeNativeBody(block.Body(), obj, schema, false)
+}
+
+// nativeValueTokens returns the tokens of value as an expression.
+func nativeValueTokens(value interface{}) (hclwrite.Tokens, error) {
+	switch v := value.(type) {
+	case nil:
+		return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte("null")}}, nil
+	case bool:
+		return hclwrite.TokensForValue(cty.BoolVal(v)), nil
+	case json.Number:
+		return hclwrite.Tokens{{Type: hclsyntax.TokenNumberLit, Bytes: []byte(v)}}, nil
+	case string:
+		return nativeStringTokens(v), nil
+	case []interface{}:
+		tokens := hclwrite.Tokens{{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")}}
+		for i, elem := range v {
+			if i > 0 {
+				tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+			}
+			elemTokens, err := nativeValueTokens(elem)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, elemTokens...)
+		}
+		return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")}), nil
+	case jsonObj:
+		tokens := hclwrite.Tokens{{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")}}
+		if len(v) == 0 {
+			return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")}), nil
+		}
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			tokens = append(tokens, nativeKeyTokens(key)...)
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte("=")})
+			elemTokens, err := nativeValueTokens(v[key])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, elemTokens...)
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+		}
+		return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")}), nil
+	}
+	return nil, newError(CodeInvalidValue, nil, fmt.Sprintf("unsupported value %T", value), nil)
+}
+
+// nativeKeyTokens returns the tokens of an object key: an identifier, the
+// expression of a whole "${...}" key in parentheses, or a quoted string.
+func nativeKeyTokens(key string) hclwrite.Tokens {
+	if hclsyntax.ValidIdentifier(key) {
+		return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte(key)}}
+	}
+	if expr, ok := nativeWrappedExpr(key); ok {
+		return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte("(" + expr + ")")}}
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key))
+}
+
+// nativeStringTokens returns the tokens of s: the expression of a whole
+// "${...}" string, a quoted template for strings interpolating expressions
+// in "${...}" or between template markers, or else a quoted string.
+func nativeStringTokens(s string) hclwrite.Tokens {
+	if expr, ok := nativeWrappedExpr(s); ok {
+		if strings.Contains(expr, "\n") {
+			expr = "(" + expr + ")"
+		}
+		return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte(expr)}}
+	}
+
+	src := unmarkTemplate(s)
+	expr, diags := hclsyntax.ParseTemplate([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return hclwrite.TokensForValue(cty.StringVal(s))
+	}
+	if template, ok := expr.(*hclsyntax.TemplateExpr); !ok || template.IsStringLiteral() {
+		return hclwrite.TokensForValue(cty.StringVal(s))
+	}
+
+	// keep the template as is, escaping only its literal parts for a
+	// quoted string
+	tokens, _ := hclsyntax.LexTemplate([]byte(src), "", hcl.Pos{Line: 1, Column: 1})
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	depth, offset := 0, 0
+	for _, token := range tokens {
+		// the spaces between the tokens of an interpolation
+		buf.WriteString(src[offset:token.Range.Start.Byte])
+		offset = token.Range.End.Byte
+		switch token.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		case hclsyntax.TokenStringLit:
+			if depth == 0 {
+				buf.Write(escapeTemplateLiteral(token.Bytes))
+				continue
+			}
+		}
+		buf.Write(token.Bytes)
+	}
+	buf.WriteByte('"')
+	return hclwrite.Tokens{{Type: hclsyntax.TokenQuotedLit, Bytes: buf.Bytes()}}
+}
+
+// nativeWrappedExpr returns the source of the expression s consists of, if
+// s is a single "${...}".
+func nativeWrappedExpr(s string) (string, bool) {
+	if !strings.HasPrefix(s, "${") {
+		return "", false
+	}
+	expr, diags := hclsyntax.ParseTemplate([]byte(s), "", hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return "", false
+	}
+	wrap, ok := expr.(*hclsyntax.TemplateWrapExpr)
+	if !ok {
+		return "", false
+	}
+	r := wrap.Wrapped.Range()
+	return s[r.Start.Byte:r.End.Byte], true
+}
+
+// unmarkTemplate turns the @@@{ and }@@@ markers of s into "${" and "}".
+func unmarkTemplate(s string) string {
+	if !strings.Contains(s, "@@@{") {
+		return s
+	}
+	return strings.NewReplacer("@@@{", "${", "}@@@", "}").Replace(s)
+}
+
+// escapeTemplateLiteral escapes the literal part of a template for a quoted
+// string. Unlike hclwrite's escaping, "${" and "%{" are left alone, since
+// they are escaped in the template already.
+func escapeTemplateLiteral(lit []byte) []byte {
+	var buf bytes.Buffer
+	for _, r := range string(lit) {
+		switch r {
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,33 @@
+package convert
+
+import (
+	"fmt"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// SafeConvert is like ConvertWithOptions, but recovers from a panic in the
+// parser or the converter and reports it as a CodeInternal error diagnostic
+// about the block or expression being converted, if any, so that one input
+// cannot bring down a service converting untrusted configurations.
+func SafeConvert(bytes []byte, filename string, opts Options) ([]byte, hcl.Diagnostics) {
+	return NewConverter(opts).SafeConvert(bytes, filename)
+}
+
+// SafeConvert is like the package-level SafeConvert, using c's options and
+// expression strategies.
+func (c *Converter) SafeConvert(bytes []byte, filename string) (result []byte, diags hcl.Diagnostics) {
+	defer func() {
+		if r := recover(); r != nil {
+			var subject *hcl.Range
+			if c.converting != (hcl.Range{}) {
+				subject = &c.converting
+			}
+			result = nil
+			diags = append(diags, newError(CodeInternal, subject, fmt.Sprintf("panic: %v", r), nil).Diagnostic())
+		}
+	}()
+
+	artifacts := c.convertAll(bytes, filename)
+	return artifacts.JSON, artifacts.Diagnostics
+}
@@ -0,0 +1,154 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// sarifParseRule is the rule of diagnostics without a Code, such as those
+// of the HCL parser.
+const sarifParseRule = "hcl"
+
+// WriteSARIF writes diags as a SARIF 2.1.0 log with a single run, for
+// code-scanning tools. The rule of a diagnostic is its Code, or "hcl" for
+// the diagnostics of the parser; file names are written as relative URIs.
+// files maps file names to the files the diagnostics refer to, like for
+// DiagnosticsToJSON: HCL counts columns in grapheme clusters and SARIF in
+// code points, so columns are counted from the source, and left out for
+// files missing from it.
+func WriteSARIF(w io.Writer, diags hcl.Diagnostics, files map[string]*hcl.File) error {
+	run := sarifRun{
+		Tool:       sarifTool{Driver: sarifDriver{Name: "hcljson", InformationURI: "https://github.com/tmax-cloud/hcljson"}},
+		ColumnKind: "unicodeCodePoints",
+		Results:    make([]sarifResult, 0, len(diags)),
+	}
+	ruleIndex := make(map[string]int)
+	for _, diag := range diags {
+		id, description := sarifRule(diag)
+		index, ok := ruleIndex[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifDescriptor{ID: id, ShortDescription: sarifMessage{Text: description}})
+		}
+
+		result := sarifResult{RuleID: id, RuleIndex: index, Level: "error", Message: sarifMessage{Text: diag.Summary}}
+		if diag.Severity == hcl.DiagWarning {
+			result.Level = "warning"
+		}
+		if diag.Detail != "" {
+			result.Message.Text += "; " + diag.Detail
+		}
+		if diag.Subject != nil {
+			r := diag.Subject
+			location := sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(r.Filename)}).String()},
+			}
+			// SARIF lines start at 1; a zero range only names the file
+			if r.Start.Line > 0 {
+				location.Region = &sarifRegion{StartLine: r.Start.Line, EndLine: r.End.Line}
+				if file := files[r.Filename]; file != nil {
+					location.Region.StartColumn = sarifColumn(file.Bytes, r.Start)
+					location.Region.EndColumn = sarifColumn(file.Bytes, r.End)
+				}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifColumn returns the column of pos in code points, counted from the
+// start of its line in src, or 0 if pos does not fit src.
+func sarifColumn(src []byte, pos hcl.Pos) int {
+	if pos.Byte < 0 || pos.Byte > len(src) {
+		return 0
+	}
+	lineStart := bytes.LastIndexByte(src[:pos.Byte], '\n') + 1
+	return utf8.RuneCount(src[lineStart:pos.Byte]) + 1
+}
+
+// sarifRule returns the rule id and description of diag, taken from the
+// code its summary starts with.
+func sarifRule(diag *hcl.Diagnostic) (id, description string) {
+	if i := strings.Index(diag.Summary, ": "); i >= 0 {
+		if code := Code(diag.Summary[:i]); code.Summary() != "" {
+			return string(code), code.Summary()
+		}
+	}
+	return sarifParseRule, "HCL diagnostic"
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string            `json:"name"`
+	InformationURI string            `json:"informationUri"`
+	Rules          []sarifDescriptor `json:"rules,omitempty"`
+}
+
+type sarifDescriptor struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
@@ -0,0 +1,59 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+func TestWriteSARIFColumns(t *testing.T) {
+	// "é" is one grapheme cluster of two code points
+	src := []byte("a = \"é\" }\n")
+	_, diags := SafeConvert(src, "a.tf", Options{})
+	if !diags.HasErrors() {
+		t.Fatal("want a syntax error")
+	}
+	if diags[0].Subject.Start.Column != 9 {
+		t.Fatalf("got HCL column %d, want 9", diags[0].Subject.Start.Column)
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]*hcl.File
+		want  int
+	}{
+		{"known file", map[string]*hcl.File{"a.tf": {Bytes: src}}, 10},
+		{"unknown file", nil, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSARIF(&buf, diags[:1], test.files); err != nil {
+				t.Fatal(err)
+			}
+			var log struct {
+				Runs []struct {
+					Results []struct {
+						Locations []struct {
+							PhysicalLocation struct {
+								Region map[string]int `json:"region"`
+							} `json:"physicalLocation"`
+						} `json:"locations"`
+					} `json:"results"`
+				} `json:"runs"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+				t.Fatal(err)
+			}
+			region := log.Runs[0].Results[0].Locations[0].PhysicalLocation.Region
+			if region["startLine"] != 1 || region["startColumn"] != test.want {
+				t.Errorf("got region %v, want line 1, column %d", region, test.want)
+			}
+			if _, ok := region["endColumn"]; ok != (test.want != 0) {
+				t.Errorf("got region %v", region)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package convert
+
+import (
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyconvert "github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// simplifyContext returns the built-in functions, or those of Terraform
+// with Options.TerraformFunctions, extended with the variables and
+// functions of Options.EvalContext and Options.Variables. The functions
+// charge the collections they return against Options.MaxExpansion.
+func (c *Converter) simplifyContext() *hcl.EvalContext {
+	opts := c.opts
+	if !c.evaluates() {
+		return &evalContext
+	}
+	builtins := evalContext.Functions
+	if opts.TerraformFunctions {
+		builtins = TerraformFunctions()
+	}
+
+	ctx := &hcl.EvalContext{Functions: make(map[string]function.Function, len(builtins))}
+	for name, fn := range builtins {
+		ctx.Functions[name] = c.chargedFunc(fn)
+	}
+	if opts.EvalContext != nil {
+		ctx.Variables = opts.EvalContext.Variables
+		for name, fn := range opts.EvalContext.Functions {
+			ctx.Functions[name] = c.chargedFunc(fn)
+		}
+	}
+	if opts.Variables != nil {
+		ctx.Variables = withVariables(ctx.Variables, opts.Variables)
+	}
+	return ctx
+}
+
+// withVariables returns a copy of variables with vars merged into var.
+func withVariables(variables map[string]cty.Value, vars map[string]cty.Value) map[string]cty.Value {
+	merged := make(map[string]cty.Value, len(variables)+1)
+	for name, val := range variables {
+		merged[name] = val
+	}
+	attrs := make(map[string]cty.Value, len(vars))
+	if base, ok := merged["var"]; ok && base.Type().IsObjectType() && base.IsKnown() && !base.IsNull() {
+		for name, val := range base.AsValueMap() {
+			attrs[name] = val
+		}
+	}
+	for name, val := range vars {
+		attrs[name] = val
+	}
+	merged["var"] = cty.ObjectVal(attrs)
+	return merged
+}
+
+// simplified evaluates expr when Options.Simplify is set and reports
+// whether it resolved to a known value. Expressions with references to
+// anything undefined are left alone.
+func (c *Converter) simplified(expr hclsyntax.Expression) (cty.Value, bool) {
+	val, ok := c.evaluate(expr)
+	if ok {
+		c.countSimplified()
+	}
+	return val, ok
+}
+
+// evaluates reports whether expressions are evaluated at all, which
+// Simplify, Variables and InlineLocals do.
+func (c *Converter) evaluates() bool {
+	return c.opts.Simplify || c.opts.Variables != nil || c.opts.InlineLocals
+}
+
+// evaluate is simplified without counting the expression in the report.
+func (c *Converter) evaluate(expr hclsyntax.Expression) (cty.Value, bool) {
+	if !c.evaluates() {
+		return cty.NilVal, false
+	}
+
+	before := c.expanded
+	restore := c.chargeIterations(expr)
+	val, diags := expr.Value(c.evalCtx)
+	restore()
+	c.warnExpansion(expr, before)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// simplifiedString is simplified for a part of a string template.
+func (c *Converter) simplifiedString(expr hclsyntax.Expression) (string, bool) {
+	val, ok := c.evaluate(expr)
+	if !ok || val.IsNull() {
+		return "", false
+	}
+	s, err := ctyconvert.Convert(val, cty.String)
+	if err != nil {
+		return "", false
+	}
+	c.countSimplified()
+	return s.AsString(), true
+}
+
+// simplifiedBranch returns the branch of a conditional whose condition
+// resolves, so that it alone is converted even if it cannot be evaluated.
+func (c *Converter) simplifiedBranch(expr *hclsyntax.ConditionalExpr) (hclsyntax.Expression, bool) {
+	cond, ok := c.evaluate(expr.Condition)
+	if !ok || cond.IsNull() {
+		return nil, false
+	}
+	cond, err := ctyconvert.Convert(cond, cty.Bool)
+	if err != nil {
+		return nil, false
+	}
+	c.countSimplified()
+	if cond.True() {
+		return expr.TrueResult, true
+	}
+	return expr.FalseResult, true
+}
@@ -0,0 +1,48 @@
+package convert
+
+import (
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SplitByBlockType converts the input once and returns one JSON document
+// per top-level block type, keyed by that type. Each document keeps the
+// block type as its top-level key, e.g. {"variable": {...}}, so it is
+// still a valid Terraform JSON file such as variables.tf.json. Top-level
+// attributes are gathered in the document keyed by "".
+func SplitByBlockType(bytes []byte, filename string, opts Options) (map[string][]byte, error) {
+	file, diags := hclsyntax.ParseConfig(bytes, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() && !opts.Tolerant {
+		return nil, newError(CodeSyntaxError, diags[0].Subject, "parse config", diags)
+	}
+
+	convertedFile, err := NewConverter(opts).convertFile(file, diags)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]jsonObj)
+	body := file.Body.(*hclsyntax.Body)
+	for _, block := range body.Blocks {
+		if value, exists := convertedFile[block.Type]; exists {
+			groups[block.Type] = jsonObj{block.Type: value}
+		}
+	}
+	for key := range body.Attributes {
+		if groups[""] == nil {
+			groups[""] = make(jsonObj)
+		}
+		groups[""][key] = convertedFile[key]
+	}
+
+	documents := make(map[string][]byte, len(groups))
+	for blockType, group := range groups {
+		jsonBytes, err := encodeJSON(group)
+		if err != nil {
+			return nil, err
+		}
+		documents[blockType] = jsonBytes
+	}
+
+	return documents, nil
+}
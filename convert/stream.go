@@ -0,0 +1,379 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Convert reads HCL from r and writes its JSON representation to w. The
+// document is encoded straight to w, without an intermediate copy of the
+// JSON; the parser still needs all of the input at once. The error, if
+// any, is the hcl.Diagnostics of a failed conversion or the error of the
+// failed write.
+func Convert(r io.Reader, w io.Writer, filename string, opts Options) error {
+	return NewConverter(opts).Convert(r, w, filename)
+}
+
+// Convert is like the package-level Convert, using c's options and
+// expression strategies.
+func (c *Converter) Convert(r io.Reader, w io.Writer, filename string) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	artifacts := c.convertDocument(src, filename)
+	if artifacts.Document == nil {
+		return artifacts.Diagnostics
+	}
+	return c.encodeDocument(w, artifacts.Document)
+}
+
+// ConvertStream is like Convert, but converts and writes one top-level
+// attribute or block at a time, so that only the parsed input and a single
+// block are in memory at once. Blocks are written in the array form of the
+// HCL JSON syntax, one element per block grouped under its type, e.g.
+// {"resource": [{"aws_instance": {"web": {...}}}, ...]}, which decodes to
+// the same configuration as the document of Convert. Options.PreserveOrder,
+// Canonical, CommentKey and Encoding do not apply. The diagnostics hold the
+// warnings and collected errors of the conversion like those of
+// ConvertWithOptions, and the errors of a failed read or write. On failure,
+// the output written so far is incomplete.
+func ConvertStream(r io.Reader, w io.Writer, filename string, opts Options) hcl.Diagnostics {
+	return NewConverter(opts).ConvertStream(r, w, filename)
+}
+
+// ConvertStream is like the package-level ConvertStream, using c's options
+// and expression strategies.
+func (c *Converter) ConvertStream(r io.Reader, w io.Writer, filename string) hcl.Diagnostics {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return hcl.Diagnostics{errorDiagnostic(err)}
+	}
+	if DetectFormat(src, filename) != FormatHCL {
+		artifacts := c.convertDocument(src, filename)
+		if artifacts.Document == nil {
+			return artifacts.Diagnostics
+		}
+		if err := c.encodeDocument(w, artifacts.Document); err != nil {
+			return append(artifacts.Diagnostics, errorDiagnostic(err))
+		}
+		return artifacts.Diagnostics
+	}
+	body, diags := c.parseStream(src, filename)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	var types []string
+	blocks := make(map[string][]*hclsyntax.Block)
+	for _, block := range body.Blocks {
+		if !c.blockSelected(block) {
+			continue
+		}
+		if _, seen := blocks[block.Type]; !seen {
+			types = append(types, block.Type)
+		}
+		blocks[block.Type] = append(blocks[block.Type], block)
+	}
+
+	// Attributes are written before all blocks and blocks are grouped by
+	// type, so keys are checked against the source order as convertBody
+	// does: a key goes to whichever of an attribute and blocks comes first,
+	// and the blocks of a type all have labels or none.
+	s := &streamWriter{w: bufio.NewWriter(w)}
+	written := make(map[string]bool)
+	s.writeByte('{')
+	for _, attr := range sortedAttributes(body) {
+		if list := blocks[attr.Name]; len(list) > 0 && list[0].TypeRange.Start.Byte < attr.SrcRange.Start.Byte {
+			if err := attributeCollision(attr, nil); !c.collectError(err, attr.SrcRange) {
+				return hcl.Diagnostics{errorDiagnostic(fmt.Errorf("Unable to convert expression: %w", err))}
+			}
+			continue
+		}
+		value, ok, err := c.streamAttribute(attr)
+		if err != nil {
+			return hcl.Diagnostics{errorDiagnostic(err)}
+		}
+		if ok {
+			written[attr.Name] = true
+			s.member(attr.Name, value)
+		}
+	}
+
+	for _, blockType := range types {
+		// the key is written with the first block, so that a type whose
+		// blocks are all left out does not show
+		first := true
+		var labelled bool
+		for _, block := range blocks[blockType] {
+			if err := streamCollision(block, written[blockType], !first && labelled != (len(block.Labels) > 0)); err != nil {
+				if !c.collectError(err, block.DefRange()) {
+					return hcl.Diagnostics{errorDiagnostic(fmt.Errorf("Unable to convert block: %w", err))}
+				}
+				continue
+			}
+			value, ok, err := c.streamBlock(block)
+			if err != nil {
+				return hcl.Diagnostics{errorDiagnostic(err)}
+			}
+			if !ok {
+				continue
+			}
+			// a block without labels may come as an array of one
+			items := []interface{}{value}
+			if list, isList := value.([]interface{}); isList && len(block.Labels) == 0 {
+				items = list
+			}
+			for _, item := range items {
+				if first {
+					s.key(blockType)
+					s.writeByte('[')
+				} else {
+					s.writeByte(',')
+				}
+				first = false
+				s.value(item)
+			}
+			labelled = len(block.Labels) > 0
+		}
+		if !first {
+			s.writeByte(']')
+		}
+	}
+	s.writeByte('}')
+	s.writeByte('\n')
+	if err := s.flush(); err != nil {
+		return append(c.problems(), errorDiagnostic(err))
+	}
+	return c.problems()
+}
+
+// streamCollision returns the error convertBody reports for block if its
+// type is the name of an attribute or, if mixed is set, the blocks written
+// under its type differ from it in having labels.
+func streamCollision(block *hclsyntax.Block, attribute, mixed bool) error {
+	switch {
+	case (attribute || mixed) && len(block.Labels) > 0:
+		return labelCollision(block)
+	case attribute:
+		return blockCollision(block, "an attribute")
+	case mixed:
+		return blockCollision(block, "a label of other blocks")
+	}
+	return nil
+}
+
+// ConvertNDJSON converts native syntax like ConvertStream, but writes one
+// JSON document per line for every top-level block and attribute, in source
+// order: {"type": ..., "labels": [...], "body": {...}} for a block and
+// {"attribute": ..., "value": ...} for an attribute, so that stream
+// processors can consume a configuration record by record.
+func ConvertNDJSON(r io.Reader, w io.Writer, filename string, opts Options) error {
+	return NewConverter(opts).ConvertNDJSON(r, w, filename)
+}
+
+// ConvertNDJSON is like the package-level ConvertNDJSON, using c's options
+// and expression strategies.
+func (c *Converter) ConvertNDJSON(r io.Reader, w io.Writer, filename string) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if DetectFormat(src, filename) != FormatHCL {
+		return newError(CodeUnsupportedBody, nil, "NDJSON output takes native syntax input", nil)
+	}
+	body, diags := c.parseStream(src, filename)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	s := &streamWriter{w: bufio.NewWriter(w)}
+	attributes, blocks := sortedAttributes(body), body.Blocks
+	for len(blocks) > 0 || len(attributes) > 0 {
+		if len(blocks) > 0 && (len(attributes) == 0 || blocks[0].TypeRange.Start.Byte < attributes[0].SrcRange.Start.Byte) {
+			block := blocks[0]
+			blocks = blocks[1:]
+			value, ok, err := c.streamBlock(block)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			labels := append([]string{}, block.Labels...)
+			s.value(jsonObj{"type": block.Type, "labels": labels, "body": blockBody(value, labels)})
+			s.writeByte('\n')
+			continue
+		}
+
+		attr := attributes[0]
+		attributes = attributes[1:]
+		value, ok, err := c.streamAttribute(attr)
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.value(jsonObj{"attribute": attr.Name, "value": value})
+			s.writeByte('\n')
+		}
+	}
+	return c.finishStream(s)
+}
+
+// blockBody returns the body of a single block from the value it converts to
+// under its type, unwrapping the arrays of one a block shape may put on the
+// way. A value of another shape, e.g. one an AfterBlock hook returned, is
+// returned as is.
+func blockBody(value interface{}, labels []string) interface{} {
+	body := value
+	for _, label := range labels {
+		if list, ok := body.([]interface{}); ok && len(list) == 1 {
+			body = list[0]
+		}
+		obj, ok := body.(jsonObj)
+		if !ok {
+			return value
+		}
+		if body, ok = obj[label]; !ok {
+			return value
+		}
+	}
+	if list, ok := body.([]interface{}); ok && len(list) == 1 {
+		body = list[0]
+	}
+	return body
+}
+
+// parseStream parses src and prepares c to convert its top-level items one
+// at a time. The diagnostics are those of a failed parse.
+func (c *Converter) parseStream(src []byte, filename string) (*hclsyntax.Body, hcl.Diagnostics) {
+	if diag := c.checkNesting(src, filename); diag != nil {
+		return nil, hcl.Diagnostics{diag}
+	}
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	c.reset(src, diags)
+	body := file.Body.(*hclsyntax.Body)
+	c.checkBody(body)
+	c.checkResourceBudget(body)
+	c.resolveLocals(body)
+	if c.opts.Strict {
+		c.strictKeys = make(map[string]strictKind)
+	}
+	return body, nil
+}
+
+func sortedAttributes(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	attributes := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attributes = append(attributes, attr)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].SrcRange.Start.Byte < attributes[j].SrcRange.Start.Byte
+	})
+	return attributes
+}
+
+// streamAttribute converts a top-level attribute on its own. ok is false if
+// the attribute is left out.
+func (c *Converter) streamAttribute(attr *hclsyntax.Attribute) (value interface{}, ok bool, err error) {
+	out := make(jsonObj, 1)
+	if err := c.convertAttribute(attr, out); err != nil {
+		if !c.collectError(err, attr.SrcRange) {
+			return nil, false, fmt.Errorf("Unable to convert expression: %w", err)
+		}
+		return nil, false, nil
+	}
+	value, ok = out[attr.Name]
+	return value, ok, nil
+}
+
+// streamBlock converts a top-level block on its own and returns what it
+// converts to under its type. ok is false if the block is left out.
+func (c *Converter) streamBlock(block *hclsyntax.Block) (value interface{}, ok bool, err error) {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return nil, false, c.ctx.Err()
+	}
+	if !c.blockSelected(block) {
+		return nil, false, nil
+	}
+	out := make(jsonObj, 1)
+	if err := c.convertBlock(block, out); err != nil {
+		if !c.collectError(err, block.DefRange()) {
+			return nil, false, fmt.Errorf("Unable to convert block: %w", err)
+		}
+		return nil, false, nil
+	}
+	value, ok = out[block.Type]
+	return value, ok, nil
+}
+
+// finishStream flushes the output and returns the first write error or the
+// errors collected along the way.
+func (c *Converter) finishStream(s *streamWriter) error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if len(c.errors) > 0 {
+		return c.errors
+	}
+	return nil
+}
+
+// streamWriter writes the members of a JSON object, keeping the first
+// write error.
+type streamWriter struct {
+	w       *bufio.Writer
+	buf     bytes.Buffer
+	members int
+	err     error
+}
+
+// flush flushes the output and returns the first write error.
+func (s *streamWriter) flush() error {
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+	return s.err
+}
+
+func (s *streamWriter) writeByte(b byte) {
+	if s.err == nil {
+		s.err = s.w.WriteByte(b)
+	}
+}
+
+// key writes the key of the next member of the object.
+func (s *streamWriter) key(key string) {
+	if s.members > 0 {
+		s.writeByte(',')
+	}
+	s.members++
+	s.value(key)
+	s.writeByte(':')
+}
+
+func (s *streamWriter) member(key string, value interface{}) {
+	s.key(key)
+	s.value(value)
+}
+
+// value writes value without the newline of jsonEncoder.
+func (s *streamWriter) value(value interface{}) {
+	if s.err != nil {
+		return
+	}
+	s.buf.Reset()
+	if s.err = jsonEncoder(&s.buf, value); s.err == nil {
+		_, s.err = s.w.Write(bytes.TrimSuffix(s.buf.Bytes(), []byte("\n")))
+	}
+}
@@ -0,0 +1,65 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConvertStream(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want string
+		code Code
+	}{
+		{
+			name: "blocks by type",
+			src:  "a = 1\nb \"x\" { v = 1 }\nc { v = 2 }\nb \"y\" {}",
+			want: `{"a":1,"b":[{"x":{"v":1}},{"y":{}}],"c":[{"v":2}]}`,
+		},
+		{name: "block after attribute", src: "a = 1\na {}", code: CodeKeyCollision},
+		{name: "attribute after block", src: "a {}\na = 1", code: CodeKeyCollision},
+		{name: "labelled block after attribute", src: "a = 1\na \"x\" {}", code: CodeKeyCollision},
+		{name: "labelled block after block", src: "a {}\na \"x\" {}", code: CodeKeyCollision},
+		{name: "block after labelled block", src: "a \"x\" {}\na {}", code: CodeKeyCollision},
+		{
+			name: "collected block after attribute",
+			src:  "a = 1\na { x = 1 }\nb {}",
+			opts: Options{CollectErrors: true},
+			want: `{"a":1,"b":[{}]}`,
+			code: CodeKeyCollision,
+		},
+		{
+			name: "collected attribute after block",
+			src:  "a { x = 1 }\na = 1",
+			opts: Options{CollectErrors: true},
+			want: `{"a":[{"x":1}]}`,
+			code: CodeKeyCollision,
+		},
+		{
+			name: "warning",
+			src:  "a = \"abcdef\"\nb {}",
+			opts: Options{MaxAttributeValueSize: 4},
+			want: `{"a":"abcdef","b":[{}]}`,
+			code: CodeBudgetExceeded,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			diags := ConvertStream(strings.NewReader(test.src), &buf, "main.tf", test.opts)
+			if test.code != "" {
+				if len(diags) != 1 || diags[0].Summary != string(test.code)+": "+test.code.Summary() {
+					t.Fatalf("got %s, %v, want %s", buf.Bytes(), diags, test.code)
+				}
+			} else if len(diags) > 0 {
+				t.Fatal(diags)
+			}
+			if test.want != "" && buf.String() != test.want+"\n" {
+				t.Errorf("got %s, want %s", buf.Bytes(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package convert
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// strictKind is what a key of the body being converted was used for, as
+// tracked with Options.Strict.
+type strictKind int
+
+const (
+	strictAttribute strictKind = iota + 1
+	// strictLabel is an object holding the blocks under a type or label.
+	strictLabel
+	strictBlock
+)
+
+// checkStrictAttribute records the attribute name and fails if a block
+// already uses it, or if it is a local value already declared by another
+// top-level locals block.
+func (c *Converter) checkStrictAttribute(attr *hclsyntax.Attribute) error {
+	if c.strictKeys == nil {
+		return nil
+	}
+	pointer := Path{attr.Name}.Pointer()
+	if c.strictKeys[pointer] != 0 {
+		r := attr.NameRange
+		return newError(CodeKeyCollision, &r, fmt.Sprintf("attribute %s has the name of a block", attr.Name), nil)
+	}
+	c.strictKeys[pointer] = strictAttribute
+	if len(c.blockTypes) == 1 && c.blockTypes[0] == "locals" {
+		if c.strictLocals[attr.Name] {
+			r := attr.NameRange
+			return newError(CodeKeyCollision, &r, fmt.Sprintf("%s is declared more than once", Path{"local", attr.Name}), nil)
+		}
+		c.strictLocals[attr.Name] = true
+	}
+	return nil
+}
+
+// checkStrictBlock records the type and labels of block and fails if they
+// were used by an attribute or a block with fewer or more labels. Blocks
+// with the same labels only fail for the top-level blocks whose address
+// must be unique in a module, such as resources; nested ones like
+// provisioner or dynamic blocks are collected into arrays.
+func (c *Converter) checkStrictBlock(block *hclsyntax.Block) error {
+	if c.strictKeys == nil {
+		return nil
+	}
+	address := append(Path{block.Type}, block.Labels...)
+	for i := range address {
+		pointer := address[:i+1].Pointer()
+		kind, last := c.strictKeys[pointer], i == len(address)-1
+		detail := ""
+		switch {
+		case kind == strictAttribute:
+			detail = fmt.Sprintf("block %s has the name of an attribute", address)
+		case !last && kind == strictBlock, last && kind == strictLabel:
+			detail = fmt.Sprintf("labels of block %s conflict with another block", address)
+		// MEMO : provider는 alias로 구분되므로 같은 label로 여러 번 선언될 수 있음.
+		case last && kind == strictBlock && c.uniqueAddress(block):
+			detail = fmt.Sprintf("block %s is declared more than once", address)
+		}
+		if detail != "" {
+			r := block.DefRange()
+			return newError(CodeKeyCollision, &r, detail, nil)
+		}
+		if last {
+			c.strictKeys[pointer] = strictBlock
+		} else {
+			c.strictKeys[pointer] = strictLabel
+		}
+	}
+	return nil
+}
+
+// uniqueAddress reports whether block is a top-level block, such as a
+// resource or a variable, whose type and labels may only be declared once.
+func (c *Converter) uniqueAddress(block *hclsyntax.Block) bool {
+	labels, unique := uniqueLabels[block.Type]
+	return unique && len(c.blockTypes) == 0 && block.Type != "locals" && len(block.Labels) == labels
+}
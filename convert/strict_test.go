@@ -0,0 +1,59 @@
+package convert
+
+import "testing"
+
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		code Code
+	}{
+		{name: "duplicate resource", src: "resource \"a\" \"b\" {}\nresource \"a\" \"b\" {}", code: CodeKeyCollision},
+		{name: "duplicate variable", src: "variable \"a\" {}\nvariable \"a\" {}", code: CodeKeyCollision},
+		{name: "duplicate local", src: "locals { a = 1 }\nlocals { a = 2 }", code: CodeKeyCollision},
+		{name: "labels run into a block", src: "resource \"a\" {}\nresource \"a\" \"b\" {}", code: CodeKeyCollision},
+		{name: "attribute and block", src: "a = 1\na {}", code: CodeKeyCollision},
+		{name: "distinct resources", src: "resource \"a\" \"b\" {}\nresource \"a\" \"c\" {}"},
+		{name: "distinct locals", src: "locals { a = 1 }\nlocals { b = 2 }"},
+		{name: "providers", src: "provider \"aws\" {}\nprovider \"aws\" {\n  alias = \"b\"\n}"},
+		{
+			name: "provisioners",
+			src: `resource "a" "b" {
+  provisioner "local-exec" {
+    command = "x"
+  }
+  provisioner "local-exec" {
+    command = "y"
+  }
+}`,
+		},
+		{
+			name: "dynamic blocks",
+			src: `resource "a" "b" {
+  dynamic "c" {
+    for_each = []
+    content {}
+  }
+  dynamic "c" {
+    for_each = []
+    content {}
+  }
+}`,
+		},
+		{name: "nested duplicate", src: "a {\n  b \"x\" {}\n  b \"x\" {}\n}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, diags := ConvertWithOptions([]byte(test.src), "main.tf", Options{Strict: true})
+			if test.code == "" {
+				if diags.HasErrors() {
+					t.Fatal(diags)
+				}
+				return
+			}
+			if !diags.HasErrors() || diags[0].Summary != string(test.code)+": "+test.code.Summary() {
+				t.Errorf("got %v, want %s", diags, test.code)
+			}
+		})
+	}
+}
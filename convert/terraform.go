@@ -0,0 +1,91 @@
+package convert
+
+import (
+	"strconv"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// terraformReferences lists the attributes, by the types of their enclosing
+// blocks, that Terraform's JSON syntax reads as bare references or type
+// constraints instead of "${...}" expressions.
+var terraformReferences = map[string]bool{
+	"resource.depends_on":                     true,
+	"resource.provider":                       true,
+	"resource.lifecycle.ignore_changes":       true,
+	"resource.lifecycle.replace_triggered_by": true,
+	"data.depends_on":                         true,
+	"data.provider":                           true,
+	"module.depends_on":                       true,
+	"module.providers":                        true,
+	"output.depends_on":                       true,
+	"variable.type":                           true,
+	"moved.from":                              true,
+	"moved.to":                                true,
+	"import.to":                               true,
+	"import.provider":                         true,
+	"removed.from":                            true,
+}
+
+func (c *Converter) isTerraformReference(name string) bool {
+	if !c.opts.TerraformMode {
+		return false
+	}
+	return terraformReferences[strings.Join(append(c.blockTypes[:len(c.blockTypes):len(c.blockTypes)], name), ".")]
+}
+
+// referenceValue converts expr to the bare source of the references it
+// holds, keeping lists and objects, e.g. [aws_instance.a] to
+// ["aws_instance.a"] and { aws = aws.west } to {"aws": "aws.west"}.
+func (c *Converter) referenceValue(expr hclsyntax.Expression) interface{} {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		list := make([]interface{}, 0, len(e.Exprs))
+		for _, item := range e.Exprs {
+			list = append(list, c.referenceValue(item))
+		}
+		return list
+	case *hclsyntax.ObjectConsExpr:
+		object := make(jsonObj)
+		for _, item := range e.Items {
+			key := hcl.ExprAsKeyword(item.KeyExpr)
+			if key == "" {
+				// MEMO : quoted key는 따옴표를 벗긴 값으로, 그 외의 key 식은 source 그대로 씀.
+				if value, diags := item.KeyExpr.Value(nil); !diags.HasErrors() && value.Type() == cty.String {
+					key = value.AsString()
+				} else {
+					key = c.rangeSource(item.KeyExpr.Range())
+				}
+			}
+			object[key] = c.referenceValue(item.ValueExpr)
+		}
+		return object
+	case *hclsyntax.TemplateExpr:
+		if e.IsStringLiteral() {
+			value, _ := e.Value(nil)
+			return value.AsString()
+		}
+	}
+	return c.rangeSource(expr.Range())
+}
+
+// isTerraformProvisioner reports whether block is a provisioner to emit as
+// an element of the provisioner array in TerraformMode.
+func (c *Converter) isTerraformProvisioner(block *hclsyntax.Block) bool {
+	return c.opts.TerraformMode && block.Type == "provisioner" && len(block.Labels) == 1
+}
+
+// appendProvisioner appends the provisioner block, converted to value, to
+// the provisioner array of out as {"<type>": value}: Terraform runs
+// provisioners in order, which an object keyed by type would lose.
+func (c *Converter) appendProvisioner(at Path, block *hclsyntax.Block, out jsonObj, value interface{}) {
+	list, exists := out[block.Type].([]interface{})
+	if !exists {
+		c.recordKey(at, block.Type)
+	}
+	c.recordKey(append(at, block.Type, strconv.Itoa(len(list))), block.Labels[0])
+	out[block.Type] = append(list, jsonObj{block.Labels[0]: value})
+}
@@ -0,0 +1,501 @@
+package convert
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyconvert "github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// TerraformFunctions returns the pure built-in functions of Terraform, the
+// ones whose result only depends on their arguments, for Simplify to
+// evaluate with Options.TerraformFunctions. Functions reading files, the
+// clock or random sources, such as file, timestamp or uuid, and try and
+// can, whose result would depend on what is left unresolved, are not
+// included. setproduct fails instead of returning more than
+// maxExpandedElements elements, like range does past 1024.
+func TerraformFunctions() map[string]function.Function {
+	functions := make(map[string]function.Function, len(terraformFunctions))
+	for name, fn := range terraformFunctions {
+		functions[name] = fn
+	}
+	return functions
+}
+
+var terraformFunctions = map[string]function.Function{
+	// numeric
+	"abs":      stdlib.AbsoluteFunc,
+	"ceil":     stdlib.CeilFunc,
+	"floor":    stdlib.FloorFunc,
+	"log":      stdlib.LogFunc,
+	"max":      stdlib.MaxFunc,
+	"min":      stdlib.MinFunc,
+	"parseint": stdlib.ParseIntFunc,
+	"pow":      stdlib.PowFunc,
+	"signum":   stdlib.SignumFunc,
+
+	// string
+	"chomp":      stdlib.ChompFunc,
+	"format":     stdlib.FormatFunc,
+	"formatlist": stdlib.FormatListFunc,
+	"indent":     stdlib.IndentFunc,
+	"join":       stdlib.JoinFunc,
+	"lower":      stdlib.LowerFunc,
+	"regex":      stdlib.RegexFunc,
+	"regexall":   stdlib.RegexAllFunc,
+	"replace":    replaceFunc,
+	"split":      stdlib.SplitFunc,
+	"strrev":     stdlib.ReverseFunc,
+	"substr":     stdlib.SubstrFunc,
+	"title":      stdlib.TitleFunc,
+	"trim":       stdlib.TrimFunc,
+	"trimprefix": stdlib.TrimPrefixFunc,
+	"trimsuffix": stdlib.TrimSuffixFunc,
+	"trimspace":  stdlib.TrimSpaceFunc,
+	"upper":      stdlib.UpperFunc,
+
+	// collections
+	"alltrue":         boolReduceFunc(true),
+	"anytrue":         boolReduceFunc(false),
+	"chunklist":       stdlib.ChunklistFunc,
+	"coalesce":        stdlib.CoalesceFunc,
+	"coalescelist":    stdlib.CoalesceListFunc,
+	"compact":         stdlib.CompactFunc,
+	"concat":          stdlib.ConcatFunc,
+	"contains":        stdlib.ContainsFunc,
+	"distinct":        stdlib.DistinctFunc,
+	"element":         stdlib.ElementFunc,
+	"flatten":         stdlib.FlattenFunc,
+	"index":           stdlib.IndexFunc,
+	"keys":            stdlib.KeysFunc,
+	"length":          lengthFunc,
+	"lookup":          stdlib.LookupFunc,
+	"merge":           stdlib.MergeFunc,
+	"one":             oneFunc,
+	"range":           stdlib.RangeFunc,
+	"reverse":         stdlib.ReverseListFunc,
+	"setintersection": stdlib.SetIntersectionFunc,
+	"setproduct":      boundedFunc(stdlib.SetProductFunc, productLength),
+	"setsubtract":     stdlib.SetSubtractFunc,
+	"setunion":        stdlib.SetUnionFunc,
+	"slice":           stdlib.SliceFunc,
+	"sort":            stdlib.SortFunc,
+	"sum":             sumFunc,
+	"values":          stdlib.ValuesFunc,
+	"zipmap":          stdlib.ZipmapFunc,
+
+	// encoding
+	"base64decode": base64DecodeFunc,
+	"base64encode": stringFunc(func(s string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	}),
+	"csvdecode":  stdlib.CSVDecodeFunc,
+	"jsondecode": stdlib.JSONDecodeFunc,
+	"jsonencode": stdlib.JSONEncodeFunc,
+	"urlencode": stringFunc(func(s string) (string, error) {
+		return url.QueryEscape(s), nil
+	}),
+
+	// time
+	"formatdate": stdlib.FormatDateFunc,
+	"timeadd":    stdlib.TimeAddFunc,
+
+	// hash
+	"base64sha256": hashFunc(sha256.New, base64.StdEncoding.EncodeToString),
+	"base64sha512": hashFunc(sha512.New, base64.StdEncoding.EncodeToString),
+	"md5":          hashFunc(md5.New, hex.EncodeToString),
+	"sha1":         hashFunc(sha1.New, hex.EncodeToString),
+	"sha256":       hashFunc(sha256.New, hex.EncodeToString),
+	"sha512":       hashFunc(sha512.New, hex.EncodeToString),
+
+	// ip network
+	"cidrhost":    cidrHostFunc,
+	"cidrnetmask": cidrNetmaskFunc,
+	"cidrsubnet":  cidrSubnetFunc,
+	"cidrsubnets": cidrSubnetsFunc,
+
+	// type conversion
+	"tobool":   toFunc(cty.Bool),
+	"tolist":   toFunc(cty.List(cty.DynamicPseudoType)),
+	"tomap":    toFunc(cty.Map(cty.DynamicPseudoType)),
+	"tonumber": toFunc(cty.Number),
+	"toset":    toFunc(cty.Set(cty.DynamicPseudoType)),
+	"tostring": toFunc(cty.String),
+}
+
+// maxExpandedElements bounds the elements of the sets setproduct returns,
+// so that a short expression such as setproduct(range(1000), range(1000),
+// range(1000)) cannot exhaust the memory of the converter.
+const maxExpandedElements = 10000
+
+// boundedFunc returns fn failing if the result would have more than
+// maxExpandedElements elements, as told by length before calling fn.
+func boundedFunc(fn function.Function, length func(args []cty.Value) *big.Float) function.Function {
+	return function.New(&function.Spec{
+		Params:   fn.Params(),
+		VarParam: fn.VarParam(),
+		Type:     fn.ReturnTypeForValues,
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if n := length(args); n != nil && n.Cmp(big.NewFloat(maxExpandedElements)) > 0 {
+				return cty.UnknownVal(retType), fmt.Errorf("the result would have %.0f elements, more than the %d allowed", n, maxExpandedElements)
+			}
+			return fn.Call(args)
+		},
+	})
+}
+
+// productLength returns the number of elements of setproduct(args...), or
+// nil if setproduct fails on args anyway.
+func productLength(args []cty.Value) *big.Float {
+	n := big.NewFloat(1)
+	for _, arg := range args {
+		if !arg.IsKnown() || arg.IsNull() || !arg.CanIterateElements() {
+			return nil
+		}
+		n.Mul(n, big.NewFloat(float64(arg.LengthInt())))
+	}
+	return n
+}
+
+// stringFunc returns a function of one string to a string.
+func stringFunc(fn func(string) (string, error)) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "str", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			s, err := fn(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(s), nil
+		},
+	})
+}
+
+var base64DecodeFunc = stringFunc(func(s string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode base64 data: %w", err)
+	}
+	if !utf8.Valid(decoded) {
+		return "", fmt.Errorf("the result of decoding the given base64 string is not valid UTF-8")
+	}
+	return string(decoded), nil
+})
+
+// hashFunc returns a function hashing a string and encoding the digest.
+func hashFunc(newHash func() hash.Hash, encode func([]byte) string) function.Function {
+	return stringFunc(func(s string) (string, error) {
+		h := newHash()
+		h.Write([]byte(s))
+		return encode(h.Sum(nil)), nil
+	})
+}
+
+// replaceFunc is Terraform's replace, which takes a substring between
+// slashes as a regular expression.
+var replaceFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "str", Type: cty.String},
+		{Name: "substr", Type: cty.String},
+		{Name: "replace", Type: cty.String},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		str, substr, replace := args[0].AsString(), args[1].AsString(), args[2].AsString()
+		if len(substr) > 1 && substr[0] == '/' && substr[len(substr)-1] == '/' {
+			re, err := regexp.Compile(substr[1 : len(substr)-1])
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(re.ReplaceAllString(str, replace)), nil
+		}
+		return cty.StringVal(strings.Replace(str, substr, replace, -1)), nil
+	},
+})
+
+// lengthFunc is Terraform's length, which also counts the characters of a
+// string.
+var lengthFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "value", Type: cty.DynamicPseudoType, AllowDynamicType: true}},
+	Type:   function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		if args[0].Type() == cty.String {
+			return stdlib.Strlen(args[0])
+		}
+		return stdlib.Length(args[0])
+	},
+})
+
+// elements returns the elements of a list, set or tuple.
+func elements(collection cty.Value) ([]cty.Value, error) {
+	ty := collection.Type()
+	if !ty.IsListType() && !ty.IsSetType() && !ty.IsTupleType() {
+		return nil, function.NewArgErrorf(0, "a list, set or tuple is required")
+	}
+	var values []cty.Value
+	for it := collection.ElementIterator(); it.Next(); {
+		_, value := it.Element()
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+var sumFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "list", Type: cty.DynamicPseudoType}},
+	Type:   function.StaticReturnType(cty.Number),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		values, err := elements(args[0])
+		if err != nil {
+			return cty.UnknownVal(cty.Number), err
+		}
+		if len(values) == 0 {
+			return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "cannot sum an empty list")
+		}
+		sum := cty.Zero
+		for _, value := range values {
+			number, err := ctyconvert.Convert(value, cty.Number)
+			if err != nil || number.IsNull() {
+				return cty.UnknownVal(cty.Number), function.NewArgErrorf(0, "every element must be a number")
+			}
+			sum = sum.Add(number)
+		}
+		return sum, nil
+	},
+})
+
+// boolReduceFunc returns alltrue, for all, or anytrue.
+func boolReduceFunc(all bool) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "list", Type: cty.DynamicPseudoType}},
+		Type:   function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			values, err := elements(args[0])
+			if err != nil {
+				return cty.UnknownVal(cty.Bool), err
+			}
+			for _, value := range values {
+				b, err := ctyconvert.Convert(value, cty.Bool)
+				if err != nil {
+					return cty.UnknownVal(cty.Bool), function.NewArgErrorf(0, "every element must be a bool")
+				}
+				if !b.IsNull() && b.True() != all {
+					return cty.BoolVal(!all), nil
+				}
+				if b.IsNull() && all {
+					return cty.False, nil
+				}
+			}
+			return cty.BoolVal(all), nil
+		},
+	})
+}
+
+var oneFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "list", Type: cty.DynamicPseudoType}},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		ty := args[0].Type()
+		switch {
+		case ty.IsListType() || ty.IsSetType():
+			return ty.ElementType(), nil
+		case ty.IsTupleType() && len(ty.TupleElementTypes()) <= 1:
+			if len(ty.TupleElementTypes()) == 0 {
+				return cty.DynamicPseudoType, nil
+			}
+			return ty.TupleElementTypes()[0], nil
+		}
+		return cty.NilType, function.NewArgErrorf(0, "must be a list, set or tuple of at most one element")
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		values, err := elements(args[0])
+		if err != nil {
+			return cty.UnknownVal(retType), err
+		}
+		switch len(values) {
+		case 0:
+			return cty.NullVal(retType), nil
+		case 1:
+			return values[0], nil
+		}
+		return cty.UnknownVal(retType), function.NewArgErrorf(0, "must be a list, set or tuple of at most one element")
+	},
+})
+
+// toFunc returns a function converting its argument to ty, like Terraform's
+// tostring and the like.
+func toFunc(ty cty.Type) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "v", Type: cty.DynamicPseudoType, AllowNull: true, AllowDynamicType: true}},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			converted, err := ctyconvert.Convert(args[0], ty)
+			if err != nil {
+				return cty.NilType, function.NewArgError(0, err)
+			}
+			return converted.Type(), nil
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			converted, err := ctyconvert.Convert(args[0], retType)
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			return converted, nil
+		},
+	})
+}
+
+// parseCIDR returns the first address of the prefix as an integer, its
+// prefix length and its address length in bits.
+func parseCIDR(prefix string) (base *big.Int, ones, bits int, err error) {
+	_, network, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, 0, 0, function.NewArgErrorf(0, "invalid CIDR expression: %s", err)
+	}
+	ones, bits = network.Mask.Size()
+	return new(big.Int).SetBytes(network.IP), ones, bits, nil
+}
+
+// formatIP renders the address n of the given length in bits.
+func formatIP(n *big.Int, bits int) string {
+	ip := make(net.IP, bits/8)
+	n.FillBytes(ip)
+	return ip.String()
+}
+
+// integerArg returns the number args[i] as an integer.
+func integerArg(args []cty.Value, i int) (*big.Int, error) {
+	n, accuracy := args[i].AsBigFloat().Int(nil)
+	if accuracy != big.Exact {
+		return nil, function.NewArgErrorf(i, "must be a whole number")
+	}
+	return n, nil
+}
+
+var cidrHostFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "hostnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		base, ones, bits, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		hostnum, err := integerArg(args, 1)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+		if hostnum.Sign() < 0 {
+			hostnum.Add(hostnum, size)
+		}
+		if hostnum.Sign() < 0 || hostnum.Cmp(size) >= 0 {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(1, "prefix of %d bits has no host number %s", ones, args[1].AsBigFloat().Text('f', -1))
+		}
+		return cty.StringVal(formatIP(base.Add(base, hostnum), bits)), nil
+	},
+})
+
+var cidrNetmaskFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "prefix", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		_, ones, bits, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		if bits != 32 {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "only IPv4 prefixes have a netmask")
+		}
+		return cty.StringVal(net.IP(net.CIDRMask(ones, bits)).String()), nil
+	},
+})
+
+var cidrSubnetFunc = function.New(&function.Spec{
+	Params: []function.Parameter{
+		{Name: "prefix", Type: cty.String},
+		{Name: "newbits", Type: cty.Number},
+		{Name: "netnum", Type: cty.Number},
+	},
+	Type: function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		base, ones, bits, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		newbits, err := integerArg(args, 1)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		netnum, err := integerArg(args, 2)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		if newbits.Sign() < 0 || !newbits.IsInt64() || ones+int(newbits.Int64()) > bits {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(1, "would extend prefix to %s bits, which is too long for an address of %d bits", new(big.Int).Add(newbits, big.NewInt(int64(ones))), bits)
+		}
+		length := ones + int(newbits.Int64())
+		if netnum.Sign() < 0 || netnum.BitLen() > int(newbits.Int64()) {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2, "prefix extension of %d bits has no network number %s", newbits, netnum)
+		}
+		base.Add(base, netnum.Lsh(netnum, uint(bits-length)))
+		return cty.StringVal(fmt.Sprintf("%s/%d", formatIP(base, bits), length)), nil
+	},
+})
+
+// cidrSubnetsFunc allocates consecutive subnets of prefix, each aligned to
+// its own size, like Terraform's cidrsubnets.
+var cidrSubnetsFunc = function.New(&function.Spec{
+	Params:   []function.Parameter{{Name: "prefix", Type: cty.String}},
+	VarParam: &function.Parameter{Name: "newbits", Type: cty.Number},
+	Type:     function.StaticReturnType(cty.List(cty.String)),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		base, ones, bits, err := parseCIDR(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(retType), err
+		}
+		end := new(big.Int).Add(base, new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)))
+
+		subnets := make([]cty.Value, 0, len(args)-1)
+		next := new(big.Int).Set(base)
+		for i := 1; i < len(args); i++ {
+			newbits, err := integerArg(args, i)
+			if err != nil {
+				return cty.UnknownVal(retType), err
+			}
+			if newbits.Sign() < 0 || !newbits.IsInt64() || ones+int(newbits.Int64()) > bits {
+				return cty.UnknownVal(retType), function.NewArgErrorf(i, "would extend prefix to %s bits, which is too long for an address of %d bits", new(big.Int).Add(newbits, big.NewInt(int64(ones))), bits)
+			}
+			length := ones + int(newbits.Int64())
+			size := new(big.Int).Lsh(big.NewInt(1), uint(bits-length))
+
+			// round up to the next multiple of size
+			start := new(big.Int).Add(next, new(big.Int).Sub(size, big.NewInt(1)))
+			start.Div(start, size).Mul(start, size)
+			next.Add(start, size)
+			if next.Cmp(end) > 0 {
+				return cty.UnknownVal(retType), function.NewArgErrorf(i, "not enough remaining address space in %s for a subnet with a prefix of %d bits", args[0].AsString(), length)
+			}
+			subnets = append(subnets, cty.StringVal(fmt.Sprintf("%s/%d", formatIP(start, bits), length)))
+		}
+		if len(subnets) == 0 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+		return cty.ListVal(subnets), nil
+	},
+})
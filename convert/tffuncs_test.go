@@ -0,0 +1,107 @@
+package convert
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestExpandingFunctions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "range", src: `a = range(3)`, want: `{"a":[0,1,2]}`},
+		{name: "long range", src: `a = range(100000)`, want: `{"a":"${range(100000)}"}`},
+		{name: "setproduct", src: `a = setproduct(["a", "b"], [1])`, want: `{"a":[["a",1],["b",1]]}`},
+		{
+			name: "large setproduct",
+			src:  `a = setproduct(range(100), range(100), range(2))`,
+			want: `{"a":"${setproduct(range(100), range(100), range(2))}"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, diags := ConvertWithOptions([]byte(test.src), "main.tf", Options{Simplify: true, TerraformFunctions: true})
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+			if string(got) != test.want+"\n" {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExpandingFunctionsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := NewConverter(Options{Simplify: true, TerraformFunctions: true}).WithContext(ctx)
+	list := cty.ListVal([]cty.Value{cty.NumberIntVal(1)})
+	calls := map[string][]cty.Value{
+		"range":      {cty.NumberIntVal(1)},
+		"setproduct": {list, list},
+	}
+	for name, args := range calls {
+		if _, err := c.evalCtx.Functions[name].Call(args); err != context.Canceled {
+			t.Errorf("%s: got %v, want %v", name, err, context.Canceled)
+		}
+	}
+}
+
+func TestExpansionBudget(t *testing.T) {
+	nested := `a = length(flatten([for i in range(150): [for j in range(150): [for k in range(150): k]]]))`
+	list := cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2), cty.NumberIntVal(3)})
+	tests := []struct {
+		name string
+		src  string
+		opts Options
+		want string
+		warn bool
+	}{
+		{
+			name: "nested ranges",
+			src:  nested,
+			opts: Options{Simplify: true, TerraformFunctions: true},
+			want: `{"a":"${length(flatten([for i in range(150): [for j in range(150): [for k in range(150): k]]]))}"}`,
+			warn: true,
+		},
+		{
+			name: "within the budget",
+			src:  `a = length(flatten([for i in range(10): [for j in range(10): [for k in range(10): k]]]))`,
+			opts: Options{Simplify: true, TerraformFunctions: true},
+			want: `{"a":1000}`,
+		},
+		{
+			name: "for iterations",
+			src:  "a = [for x in var.l : [for y in var.l : y]]\nb = 1 + 1",
+			opts: Options{Simplify: true, Variables: map[string]cty.Value{"l": list}, MaxExpansion: 10},
+			want: `{"a":"${[for x in var.l : [for y in var.l : y]]}","b":2}`,
+			warn: true,
+		},
+		{
+			name: "unbounded",
+			src:  "a = [for x in var.l : [for y in var.l : y]]",
+			opts: Options{Simplify: true, Variables: map[string]cty.Value{"l": list}, MaxExpansion: -1},
+			want: `{"a":[[1,2,3],[1,2,3],[1,2,3]]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, diags := ConvertWithOptions([]byte(test.src), "main.tf", test.opts)
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+			if string(got) != test.want+"\n" {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+			warned := len(diags) == 1 && strings.HasPrefix(diags[0].Summary, string(CodeBudgetExceeded))
+			if warned != test.warn {
+				t.Errorf("got diagnostics %v, want a budget warning: %v", diags, test.warn)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package convert
+
+import (
+	"sort"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// LoadVarFile reads the variable values of a .tfvars file, or of a
+// .tfvars.json file if filename ends in .json, for Options.Variables. Like
+// Terraform, it only accepts attribute assignments whose values are
+// constant.
+func LoadVarFile(bytes []byte, filename string) (map[string]cty.Value, hcl.Diagnostics) {
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = hcljson.Parse(bytes, filename)
+	} else {
+		file, diags = hclsyntax.ParseConfig(bytes, filename, hcl.Pos{Line: 1, Column: 1})
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	attributes, attrDiags := file.Body.JustAttributes()
+	diags = append(diags, attrDiags...)
+	values := make(map[string]cty.Value, len(attributes))
+	for name, attr := range attributes {
+		val, valDiags := attr.Expr.Value(nil)
+		diags = append(diags, valDiags...)
+		if !valDiags.HasErrors() {
+			values[name] = val
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return values, diags
+}
+
+// ConvertTfvars converts a .tfvars file to a flat .tfvars.json document.
+// Like Terraform, it only accepts attribute assignments whose values are
+// constant: blocks, references and function calls are reported as error
+// diagnostics instead of being converted.
+func ConvertTfvars(bytes []byte, filename string, opts Options) ([]byte, hcl.Diagnostics) {
+	c := NewConverter(opts)
+	if diag := c.checkNesting(bytes, filename); diag != nil {
+		return nil, hcl.Diagnostics{diag}
+	}
+	file, diags := hclsyntax.ParseConfig(bytes, filename, hcl.Pos{Line: 1, Column: 1})
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	c.bytes = file.Bytes
+	body := file.Body.(*hclsyntax.Body)
+	for _, block := range body.Blocks {
+		r := block.DefRange()
+		diags = append(diags, newError(CodeUnsupportedBody, &r,
+			"Blocks are not allowed in a variable definitions file; only attribute assignments are", nil).Diagnostic())
+	}
+
+	attributes := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attributes = append(attributes, attr)
+	}
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].SrcRange.Start.Byte < attributes[j].SrcRange.Start.Byte
+	})
+
+	out := make(jsonObj)
+	for _, attr := range attributes {
+		name := attr.Name
+		val, valDiags := attr.Expr.Value(nil)
+		diags = append(diags, valDiags...)
+		if valDiags.HasErrors() {
+			continue
+		}
+
+		var err error
+		if out[name], err = c.literal(val, attr.Expr.Range()); err != nil {
+			diags = append(diags, errorDiagnostic(err))
+		}
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	jsonBytes, err := encodeJSON(out)
+	if err != nil {
+		return nil, append(diags, errorDiagnostic(err))
+	}
+	return jsonBytes, diags
+}
@@ -0,0 +1,93 @@
+package convert
+
+import (
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+const (
+	placeholderErrorKey  = "__error__"
+	placeholderSourceKey = "__source__"
+)
+
+// invalidDiagnostic returns the first parse error whose subject overlaps the
+// given expression, or nil if the expression was parsed cleanly.
+func (c *Converter) invalidDiagnostic(expr hclsyntax.Expression) *hcl.Diagnostic {
+	exprRange := expr.Range()
+	for _, diag := range c.parseDiags {
+		if diag.Severity != hcl.DiagError || diag.Subject == nil {
+			continue
+		}
+		if diag.Subject.Overlaps(exprRange) || exprRange.ContainsPos(diag.Subject.Start) {
+			return diag
+		}
+	}
+	return nil
+}
+
+// invalidPlaceholder stands in for an expression that could not be parsed.
+func (c *Converter) invalidPlaceholder(expr hclsyntax.Expression) jsonObj {
+	summary := "Invalid expression"
+	if diag := c.invalidDiagnostic(expr); diag != nil {
+		summary = diag.Summary
+	}
+	c.skipped = append(c.skipped, SkippedItem{
+		Kind:    SkippedExpression,
+		Address: c.currentPath(),
+		Range:   expr.Range(),
+		Reason:  summary,
+	})
+
+	return jsonObj{
+		placeholderErrorKey:  summary,
+		placeholderSourceKey: c.rangeSource(expr.Range()),
+	}
+}
+
+// failedPlaceholder stands in for an expression that failed to convert, in
+// Options.Lenient mode. The failure is kept as a warning.
+func (c *Converter) failedPlaceholder(expr hclsyntax.Expression, err error) jsonObj {
+	code := CodeOf(err)
+	if code == "" {
+		code = CodeUnsupportedExpression
+	}
+	r := expr.Range()
+	c.warnings = append(c.warnings, warningDiagnostic(code, &r, err.Error()))
+	c.skipped = append(c.skipped, SkippedItem{
+		Kind:    SkippedExpression,
+		Address: c.currentPath(),
+		Range:   r,
+		Reason:  err.Error(),
+	})
+
+	return jsonObj{
+		placeholderErrorKey:  err.Error(),
+		placeholderSourceKey: c.rangeSource(r),
+	}
+}
+
+// skippedRegions returns the parse errors not already covered by a
+// placeholder. They mark source the parser discarded while recovering.
+func (c *Converter) skippedRegions() []SkippedItem {
+	var regions []SkippedItem
+	for _, diag := range c.parseDiags {
+		if diag.Severity != hcl.DiagError || diag.Subject == nil {
+			continue
+		}
+		covered := false
+		for _, item := range c.skipped {
+			if item.Range.Overlaps(*diag.Subject) || item.Range.ContainsPos(diag.Subject.Start) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			regions = append(regions, SkippedItem{
+				Kind:   SkippedRegion,
+				Range:  *diag.Subject,
+				Reason: diag.Summary,
+			})
+		}
+	}
+	return regions
+}
@@ -0,0 +1,180 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// HclToToml is like HclToJson, but encodes the document as TOML.
+func HclToToml(bytes []byte, filename string) ([]byte, error) {
+	artifacts, err := ConvertAll(bytes, filename, Options{Encoding: EncodingTOML})
+	if err != nil {
+		return nil, err
+	}
+	return artifacts.JSON, nil
+}
+
+func jsonToTOML(jsonBytes []byte) ([]byte, error) {
+	value, err := decodeOrdered(jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := value.(*orderedObject)
+	if !ok {
+		return nil, newError(CodeEncodeFailed, nil, "a TOML document must be a table", nil)
+	}
+
+	var buf bytes.Buffer
+	writeTOMLTable(&buf, root, nil, false)
+	return bytes.TrimPrefix(buf.Bytes(), []byte("\n")), nil
+}
+
+// writeTOMLTable writes the key/value pairs of table, then its tables and
+// arrays of tables, since TOML does not allow a pair after a table header.
+// The header is only written where needed: for array elements, tables
+// holding pairs and empty tables.
+func writeTOMLTable(buf *bytes.Buffer, table *orderedObject, path []string, element bool) {
+	var pairs, tables, arrays []int
+	for i, value := range table.values {
+		switch {
+		case value == nil:
+		case isTOMLTable(value):
+			tables = append(tables, i)
+		case isTOMLArrayOfTables(value):
+			arrays = append(arrays, i)
+		default:
+			pairs = append(pairs, i)
+		}
+	}
+
+	switch {
+	case element:
+		buf.WriteString("\n[[" + tomlPath(path) + "]]\n")
+	case len(path) > 0 && (len(pairs) > 0 || len(tables)+len(arrays) == 0):
+		buf.WriteString("\n[" + tomlPath(path) + "]\n")
+	}
+	for _, i := range pairs {
+		buf.WriteString(tomlKey(table.keys[i]) + " = ")
+		writeTOMLValue(buf, table.values[i])
+		buf.WriteByte('\n')
+	}
+	for _, i := range tables {
+		writeTOMLTable(buf, table.values[i].(*orderedObject), append(path[:len(path):len(path)], table.keys[i]), false)
+	}
+	for _, i := range arrays {
+		for _, item := range table.values[i].([]interface{}) {
+			writeTOMLTable(buf, item.(*orderedObject), append(path[:len(path):len(path)], table.keys[i]), true)
+		}
+	}
+}
+
+// isTOMLTable reports whether value is written as a table. Empty objects
+// are written inline.
+func isTOMLTable(value interface{}) bool {
+	object, ok := value.(*orderedObject)
+	return ok && len(object.keys) > 0
+}
+
+// isTOMLArrayOfTables reports whether value is written as an array of
+// tables, which takes a non-empty array of objects only.
+func isTOMLArrayOfTables(value interface{}) bool {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := item.(*orderedObject); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTOMLValue writes an inline value. Nulls, which TOML has no value
+// for, are left out of arrays and inline tables.
+func writeTOMLValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		buf.WriteString(tomlNumber(v))
+	case string:
+		buf.WriteString(tomlQuote(v))
+	case []interface{}:
+		buf.WriteByte('[')
+		first := true
+		for _, item := range v {
+			if item == nil {
+				continue
+			}
+			if !first {
+				buf.WriteString(", ")
+			}
+			first = false
+			writeTOMLValue(buf, item)
+		}
+		buf.WriteByte(']')
+	case *orderedObject:
+		buf.WriteByte('{')
+		first := true
+		for i, key := range v.keys {
+			if v.values[i] == nil {
+				continue
+			}
+			if first {
+				buf.WriteByte(' ')
+			} else {
+				buf.WriteString(", ")
+			}
+			first = false
+			buf.WriteString(tomlKey(key) + " = ")
+			writeTOMLValue(buf, v.values[i])
+		}
+		if !first {
+			buf.WriteByte(' ')
+		}
+		buf.WriteByte('}')
+	}
+}
+
+// tomlNumber renders a JSON number as a TOML integer or float. Integers
+// beyond 64 bits, which TOML cannot hold, are written as strings.
+func tomlNumber(number json.Number) string {
+	s := number.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// tomlPath renders the dotted key of a table header.
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	return strings.Join(keys, ".")
+}
+
+// tomlKey writes key bare if TOML allows it, quoted otherwise.
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, r := range key {
+		if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return tomlQuote(key)
+		}
+	}
+	return key
+}
+
+// tomlQuote renders s as a TOML basic string. It is the JSON string but for
+// DEL, which JSON leaves as is and TOML requires to be escaped.
+func tomlQuote(s string) string {
+	return strings.Replace(jsonQuote(s), "\x7f", `\u007f`, -1)
+}
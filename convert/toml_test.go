@@ -0,0 +1,65 @@
+package convert
+
+import "testing"
+
+func TestJSONToTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "pairs", json: `{"a":"x","b":1,"c":1.5,"d":true,"e":[1,"y"]}`, want: "a = \"x\"\nb = 1\nc = 1.5\nd = true\ne = [1, \"y\"]\n"},
+		{name: "bare keys", json: `{"a_b":1,"a-b":2,"A9":3,"9":4}`, want: "a_b = 1\na-b = 2\nA9 = 3\n9 = 4\n"},
+		{
+			name: "quoted keys",
+			json: `{"":1,"a.b":2,"a b":3,"\"q\"":4,"é":5,"a\\b":6,"x\ny":7,"\u007f":8}`,
+			want: "\"\" = 1\n\"a.b\" = 2\n\"a b\" = 3\n\"\\\"q\\\"\" = 4\n\"é\" = 5\n\"a\\\\b\" = 6\n\"x\\ny\" = 7\n\"\\u007f\" = 8\n",
+		},
+		{name: "quoted header", json: `{"a.b":{"c d":{"e":1}}}`, want: "[\"a.b\".\"c d\"]\ne = 1\n"},
+		{name: "strings", json: `{"a":"x\ty\u0001\u007f<>"}`, want: "a = \"x\\ty\\u0001\\u007f<>\"\n"},
+		{name: "null", json: `{"a":null,"b":[1,null],"c":{"d":null,"e":1}}`, want: "b = [1]\n\n[c]\ne = 1\n"},
+		{name: "inline empty", json: `{"a":{},"b":[]}`, want: "a = {}\nb = []\n"},
+		{name: "inline tables", json: `{"a":[{"b":1},2]}`, want: "a = [{ b = 1 }, 2]\n"},
+		{name: "big integer", json: `{"a":18446744073709551616,"b":-9223372036854775808}`, want: "a = \"18446744073709551616\"\nb = -9223372036854775808\n"},
+		{name: "pairs before tables", json: `{"t":{"x":1},"a":1}`, want: "a = 1\n\n[t]\nx = 1\n"},
+		{name: "implicit table", json: `{"a":{"b":{"c":1}}}`, want: "[a.b]\nc = 1\n"},
+		{
+			name: "array of tables",
+			json: `{"resource":{"aws_instance":{"web":[{"ami":"x"},{"ami":"y","tags":{"n":"z"}}]}}}`,
+			want: "[[resource.aws_instance.web]]\nami = \"x\"\n\n[[resource.aws_instance.web]]\nami = \"y\"\n\n[resource.aws_instance.web.tags]\nn = \"z\"\n",
+		},
+		{
+			name: "nested arrays of tables",
+			json: `{"a":[{"b":[{"c":1},{"c":2}],"d":{"e":3}}]}`,
+			want: "[[a]]\n\n[a.d]\ne = 3\n\n[[a.b]]\nc = 1\n\n[[a.b]]\nc = 2\n",
+		},
+		{name: "empty element", json: `{"a":[{},{"b":1}]}`, want: "[[a]]\n\n[[a]]\nb = 1\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := jsonToTOML([]byte(test.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSONToTOMLNotTable(t *testing.T) {
+	if _, err := jsonToTOML([]byte(`[1]`)); CodeOf(err) != CodeEncodeFailed {
+		t.Errorf("got %v, want %s", err, CodeEncodeFailed)
+	}
+}
+
+func TestHclToToml(t *testing.T) {
+	got, err := HclToToml([]byte("a {\n  x = 1\n}\na {\n  x = 2\n}\nb = { \"c d\" = var.y }\n"), "main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[b]\n\"c d\" = \"${var.y}\"\n\n[[a]]\nx = 1\n\n[[a]]\nx = 2\n"; string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
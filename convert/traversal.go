@@ -0,0 +1,132 @@
+package convert
+
+import (
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	referenceKey = "$ref"
+	forKey       = "$for"
+	condKey      = "$cond"
+	trueKey      = "$true"
+	falseKey     = "$false"
+)
+
+// traversalName returns the dotted name of a traversal made only of a root
+// and attribute steps, such as "path.module".
+func traversalName(traversal hcl.Traversal) (string, bool) {
+	names := make([]string, 0, len(traversal))
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, s.Name)
+		case hcl.TraverseAttr:
+			names = append(names, s.Name)
+		default:
+			return "", false
+		}
+	}
+	return strings.Join(names, "."), len(names) > 0
+}
+
+// contextValue returns the caller-provided value for a context constant
+// reference such as terraform.workspace.
+func (c *Converter) contextValue(expr *hclsyntax.ScopeTraversalExpr) (string, bool) {
+	if len(c.opts.ContextValues) == 0 {
+		return "", false
+	}
+	name, ok := traversalName(expr.Traversal)
+	if !ok {
+		return "", false
+	}
+	value, ok := c.opts.ContextValues[name]
+	return value, ok
+}
+
+// referenceObject returns the structured form of a reference, with one
+// element per traversal step: {"$ref": ["var", "list", 0]}.
+func (c *Converter) referenceObject(expr *hclsyntax.ScopeTraversalExpr) jsonObj {
+	steps := make([]interface{}, 0, len(expr.Traversal))
+	for _, step := range expr.Traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			steps = append(steps, s.Name)
+		case hcl.TraverseAttr:
+			steps = append(steps, s.Name)
+		case hcl.TraverseIndex:
+			steps = append(steps, ctyjson.SimpleJSONValue{Value: s.Key})
+		case hcl.TraverseSplat:
+			steps = append(steps, "*")
+		}
+	}
+	return jsonObj{referenceKey: steps}
+}
+
+// forObject returns the structured form of a for expression, with its parts
+// converted like any other expression:
+//
+//	{"$for": {"key": "k", "value": "v", "in": ..., "key_result": ...,
+//	          "result": ..., "if": ..., "group": true}}
+//
+// key_result is only set for object for expressions, key, if and group
+// only when present in the source.
+func (c *Converter) forObject(expr *hclsyntax.ForExpr) (jsonObj, error) {
+	out := jsonObj{"value": expr.ValVar}
+	if expr.KeyVar != "" {
+		out["key"] = expr.KeyVar
+	}
+	if expr.Group {
+		out["group"] = true
+	}
+
+	parts := []struct {
+		name string
+		expr hclsyntax.Expression
+	}{
+		{"in", expr.CollExpr},
+		{"key_result", expr.KeyExpr},
+		{"result", expr.ValExpr},
+		{"if", expr.CondExpr},
+	}
+	for _, part := range parts {
+		if part.expr == nil {
+			continue
+		}
+		leave := c.enterPath(forKey, part.name)
+		value, err := c.ConvertExpression(part.expr)
+		leave()
+		if err != nil {
+			return nil, err
+		}
+		out[part.name] = value
+	}
+	return jsonObj{forKey: out}, nil
+}
+
+// conditionalObject returns the structured form of a conditional expression:
+// {"$cond": ..., "$true": ..., "$false": ...}.
+func (c *Converter) conditionalObject(expr *hclsyntax.ConditionalExpr) (jsonObj, error) {
+	out := make(jsonObj)
+	parts := []struct {
+		name string
+		expr hclsyntax.Expression
+	}{
+		{condKey, expr.Condition},
+		{trueKey, expr.TrueResult},
+		{falseKey, expr.FalseResult},
+	}
+	for _, part := range parts {
+		leave := c.enterPath(part.name)
+		value, err := c.ConvertExpression(part.expr)
+		leave()
+		if err != nil {
+			return nil, err
+		}
+		out[part.name] = value
+	}
+	return out, nil
+}
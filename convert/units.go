@@ -0,0 +1,104 @@
+package convert
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// sizeUnits maps lower-cased size suffixes to their number of bytes.
+var sizeUnits = map[string]int64{
+	"b":  1,
+	"kb": 1000,
+	"mb": 1000 * 1000,
+	"gb": 1000 * 1000 * 1000,
+	"tb": 1000 * 1000 * 1000 * 1000,
+	"pb": 1000 * 1000 * 1000 * 1000 * 1000,
+
+	"ki":  1 << 10,
+	"mi":  1 << 20,
+	"gi":  1 << 30,
+	"ti":  1 << 40,
+	"pi":  1 << 50,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// recognizeUnits replaces the string literal of an attribute matching
+// Options.Durations with {"seconds": n} and one matching Options.Sizes
+// with its number of bytes. Strings that do not parse are kept, with a
+// warning.
+func (c *Converter) recognizeUnits(attr *hclsyntax.Attribute, value interface{}) interface{} {
+	isDuration := c.pathMatches(c.opts.Durations)
+	isSize := !isDuration && c.pathMatches(c.opts.Sizes)
+	if !isDuration && !isSize {
+		return value
+	}
+
+	template, ok := attr.Expr.(*hclsyntax.TemplateExpr)
+	if !ok || !template.IsStringLiteral() {
+		return value
+	}
+	val, diags := template.Value(nil)
+	if diags.HasErrors() {
+		return value
+	}
+	s := strings.TrimSpace(val.AsString())
+
+	r := attr.Expr.Range()
+	if isDuration {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			c.warnings = append(c.warnings, warningDiagnostic(CodeInvalidValue, &r,
+				fmt.Sprintf("The value of %s is not a duration: %v.", c.path, err)))
+			return value
+		}
+		return jsonObj{"seconds": d.Seconds()}
+	}
+
+	n, err := parseSize(s)
+	if err != nil {
+		c.warnings = append(c.warnings, warningDiagnostic(CodeInvalidValue, &r,
+			fmt.Sprintf("The value of %s is not a size: %v.", c.path, err)))
+		return value
+	}
+	return n
+}
+
+// parseSize parses sizes such as "512MiB", "1.5GB" or "100" into bytes.
+func parseSize(s string) (int64, error) {
+	i := len(s)
+	for i > 0 && (s[i-1] < '0' || s[i-1] > '9') && s[i-1] != '.' {
+		i--
+	}
+	number, unit := strings.TrimSpace(s[:i]), strings.ToLower(strings.TrimSpace(s[i:]))
+
+	multiplier := int64(1)
+	if unit != "" {
+		var ok bool
+		multiplier, ok = sizeUnits[unit]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q", s[i:])
+		}
+	}
+
+	f, ok := new(big.Float).SetString(number)
+	if !ok || f.Sign() < 0 {
+		return 0, fmt.Errorf("invalid number %q", number)
+	}
+	f.Mul(f, new(big.Float).SetInt64(multiplier))
+	if !f.IsInt() {
+		return 0, fmt.Errorf("%q is not a whole number of bytes", s)
+	}
+	bytes, accuracy := f.Int64()
+	if accuracy != big.Exact {
+		return 0, fmt.Errorf("%q is too large", s)
+	}
+	return bytes, nil
+}
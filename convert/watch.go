@@ -0,0 +1,79 @@
+package convert
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	hcl "github.com/hashicorp/hcl/v2"
+)
+
+// watchInterval is how often Watch looks for changes.
+var watchInterval = 500 * time.Millisecond
+
+// Watch is WatchContext without cancellation; it only returns on error.
+func Watch(dir string, onChange func(file string, json []byte, diags hcl.Diagnostics)) error {
+	return WatchContext(context.Background(), dir, onChange)
+}
+
+// WatchContext converts every file ConvertDir would under dir, then calls
+// onChange again for each file whose size or modification time changes,
+// until ctx is done. Only changed files are read and converted again; a
+// removed file is reported with nil json and diagnostics. The directory is
+// polled, as the module has no file notification dependency. onChange is
+// called from the goroutine of WatchContext.
+func WatchContext(ctx context.Context, dir string, onChange func(file string, json []byte, diags hcl.Diagnostics)) error {
+	type stamp struct {
+		size    int64
+		modTime time.Time
+	}
+
+	converter := NewConverter(Options{}).WithContext(ctx)
+	seen := make(map[string]stamp)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		current := make(map[string]stamp, len(seen))
+		err := filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !isConfigFile(name) {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			current[name] = stamp{info.Size(), info.ModTime()}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for name, s := range current {
+			if previous, ok := seen[name]; ok && previous == s {
+				continue
+			}
+			artifacts := convertPath(converter, name)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			onChange(name, artifacts.JSON, artifacts.Diagnostics)
+		}
+		for name := range seen {
+			if _, ok := current[name]; !ok {
+				onChange(name, nil, nil)
+			}
+		}
+		seen = current
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// HclToYaml is like HclToJson, but encodes the document as YAML.
+func HclToYaml(bytes []byte, filename string) ([]byte, error) {
+	artifacts, err := ConvertAll(bytes, filename, Options{Encoding: EncodingYAML})
+	if err != nil {
+		return nil, err
+	}
+	return artifacts.JSON, nil
+}
+
+func jsonToYAML(jsonBytes []byte) ([]byte, error) {
+	value, err := decodeOrdered(jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, value, 0)
+	return buf.Bytes(), nil
+}
+
+// writeYAML writes value at indent; the caller has already written the
+// indentation of the first line.
+func writeYAML(buf *bytes.Buffer, value interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch v := value.(type) {
+	case *orderedObject:
+		if len(v.keys) == 0 {
+			buf.WriteString("{}\n")
+			return
+		}
+		for i, key := range v.keys {
+			if i > 0 {
+				buf.WriteString(pad)
+			}
+			buf.WriteString(yamlScalar(key))
+			buf.WriteByte(':')
+			writeYAMLChild(buf, v.values[i], indent+2)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]\n")
+			return
+		}
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteString(pad)
+			}
+			buf.WriteString("- ")
+			writeYAML(buf, item, indent+2)
+		}
+	default:
+		buf.WriteString(yamlScalar(v))
+		buf.WriteByte('\n')
+	}
+}
+
+// writeYAMLChild writes the value of a mapping key, on the following lines
+// if it is a non-empty collection.
+func writeYAMLChild(buf *bytes.Buffer, value interface{}, indent int) {
+	nested := false
+	switch v := value.(type) {
+	case *orderedObject:
+		nested = len(v.keys) > 0
+	case []interface{}:
+		nested = len(v) > 0
+	}
+	if !nested {
+		buf.WriteByte(' ')
+		writeYAML(buf, value, indent)
+		return
+	}
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Repeat(" ", indent))
+	writeYAML(buf, value, indent)
+}
+
+// yamlReserved are the plain scalars YAML 1.1 and 1.2 readers resolve to
+// something other than a string.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true, "~": true,
+}
+
+// yamlScalar renders a scalar, quoting strings unless they are safe to
+// write plain: identifier-like and not a reserved word.
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return v.String()
+	case string:
+		if isPlainYAML(v) {
+			return v
+		}
+		return jsonQuote(v)
+	}
+	return ""
+}
+
+func isPlainYAML(s string) bool {
+	if s == "" || yamlReserved[strings.ToLower(s)] {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r == '-' || r == '.' || r == '/' || r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package convert
+
+import "testing"
+
+func TestJSONToYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "plain", json: `{"a":"web","b":"us-east-1","c":"a.b/c_d"}`, want: "a: web\nb: us-east-1\nc: a.b/c_d\n"},
+		{name: "booleans", json: `{"a":"yes","b":"No","c":"ON","d":"off","e":"y","f":"true"}`, want: "a: \"yes\"\nb: \"No\"\nc: \"ON\"\nd: \"off\"\ne: \"y\"\nf: \"true\"\n"},
+		{name: "nulls", json: `{"a":"null","b":"Null","c":"~","d":null}`, want: "a: \"null\"\nb: \"Null\"\nc: \"~\"\nd: null\n"},
+		{name: "numbers", json: `{"a":"1e3","b":"0x1F","c":".inf","d":"1","e":1e3,"f":-1.5}`, want: "a: \"1e3\"\nb: \"0x1F\"\nc: \".inf\"\nd: \"1\"\ne: 1e3\nf: -1.5\n"},
+		{name: "indicators", json: `{"a":"-x","b":":x","c":"a: b","d":"#x","e":"- x","f":"","g":"*x"}`, want: "a: \"-x\"\nb: \":x\"\nc: \"a: b\"\nd: \"#x\"\ne: \"- x\"\nf: \"\"\ng: \"*x\"\n"},
+		{name: "keys", json: `{"yes":1,"null":2,"-a":3,"a b":4}`, want: "\"yes\": 1\n\"null\": 2\n\"-a\": 3\n\"a b\": 4\n"},
+		{name: "escapes", json: `{"a":"x\ny","b":"<\"tab\t\">"}`, want: "a: \"x\\ny\"\nb: \"<\\\"tab\\t\\\">\"\n"},
+		{
+			name: "nested",
+			json: `{"resource":{"aws_instance":{"web":[{"ami":"yes","tags":{},"ports":[80,"443"],"empty":[]}]}}}`,
+			want: "resource:\n  aws_instance:\n    web:\n      - ami: \"yes\"\n        tags: {}\n        ports:\n          - 80\n          - \"443\"\n        empty: []\n",
+		},
+		{name: "nested lists", json: `{"a":[[1,2],[]]}`, want: "a:\n  - - 1\n    - 2\n  - []\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := jsonToYAML([]byte(test.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHclToYaml(t *testing.T) {
+	got, err := HclToYaml([]byte("a = \"yes\"\nb = var.x\nc \"d\" {\n  e = 1\n}\n"), "main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a: \"yes\"\nb: \"${var.x}\"\nc:\n  d:\n    e: 1\n"; string(got) != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -119,33 +119,6 @@ func (p *Parser) objectItem() (*ast.ObjectItem, error) {
 
 	return o, nil
 }
-func getValType(n interface{}) string {
-	switch t := n.(type) {
-	case *ast.File:
-		return "File"
-	case *ast.ObjectList:
-		fmt.Println("\033[1;33m[ObjectList]\033[0m", t.Items[0].Keys[0])
-		return "ObjectList"
-	case *ast.ObjectKey:
-		fmt.Println("\033[1;33m[ObjectKey]\033[0m", t.Token.Text)
-		return "ObjectKey"
-	case *ast.ObjectItem:
-		fmt.Println("\033[1;35m[ObjectItem]\033[0m", t.Keys[0].Token.Text)
-		return "ObjectItem"
-	case *ast.LiteralType:
-		fmt.Println("\033[1;33m[LiteralType]\033[0m", t.Token.Text)
-		return "LiteralType"
-	case *ast.ListType:
-		fmt.Println("\033[1;33m[ListType]\033[0m", t.List)
-		return "ListType"
-	case *ast.ObjectType:
-		fmt.Println("\033[1;36m[ObjectType]\033[0m", t.List.Items[0].Keys[0])
-		return "ObjectType"
-	default:
-		fmt.Printf(" unknown type: %T\n", n)
-		return "Unkown Type"
-	}
-}
 
 // objectKey parses an object key and returns a ObjectKey AST
 func (p *Parser) objectKey() ([]*ast.ObjectKey, error) {
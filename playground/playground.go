@@ -0,0 +1,28 @@
+// Package playground serves a web page for trying conversions: HCL typed
+// in one pane is converted to JSON in the other as you type.
+package playground
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/tmax-cloud/hcljson/convert"
+)
+
+//go:embed playground.html
+var page []byte
+
+// Handler returns an http.Handler serving the page on GET and converting on
+// POST, the way convert.NewHandler does, so it can be mounted on any path.
+func Handler() http.Handler {
+	converter := convert.NewHandler(convert.Options{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(page)
+		default:
+			converter.ServeHTTP(w, r)
+		}
+	})
+}
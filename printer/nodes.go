@@ -211,26 +211,26 @@ func (p *printer) output(n interface{}, typeSchema map[string]interface{}) []byt
 			index++
 		}
 	case *ast.ObjectKey:
-		fmt.Println("\033[1;33m[ObjectKey]\033[0m", t.Token.Text)
+		p.logf("[ObjectKey] %s", t.Token.Text)
 		buf.WriteString(t.Token.Text)
 	case *ast.ObjectItem:
 		p.prev = t.Pos()
-		fmt.Println("\033[1;35m[ObjectItem]\033[0m", t.Keys[0].Token.Text)
+		p.logf("[ObjectItem] %s", t.Keys[0].Token.Text)
 		buf.Write(p.objectItem(t, typeSchema))
 	case *ast.LiteralType:
-		fmt.Println("\033[1;33m[LiteralType]\033[0m", t.Token.Text)
+		p.logf("[LiteralType] %s", t.Token.Text)
 		buf.Write(p.literalType(t))
 	case *ast.ListType:
-		fmt.Println("\033[1;33m[ListType]\033[0m", t.List)
+		p.logf("[ListType] %v", t.List)
 		buf.Write(p.list(t, typeSchema))
 	case *ast.ObjectType:
 		// MEMO: 빈 프로퍼티를 가진 리소스 출력 시 오류가 발생하지 않도록 예외 처리
 		if len(t.List.Items) != 0 {
-			fmt.Println("\033[1;36m[ObjectType]\033[0m", t.List.Items[0].Keys[0])
+			p.logf("[ObjectType] %v", t.List.Items[0].Keys[0])
 		}
 		buf.Write(p.objectType(t, typeSchema))
 	default:
-		fmt.Printf(" unknown type: %T\n", n)
+		p.logf("unknown type: %T", n)
 	}
 
 	return buf.Bytes()
@@ -330,34 +330,6 @@ func (p *printer) objectItem(o *ast.ObjectItem, typeSchema map[string]interface{
 	return buf.Bytes()
 }
 
-func getValType(n interface{}) string {
-	switch t := n.(type) {
-	case *ast.File:
-		return "File"
-	case *ast.ObjectList:
-		fmt.Println("\033[1;33m[ObjectList]\033[0m", t.Items[0].Keys[0])
-		return "ObjectList"
-	case *ast.ObjectKey:
-		fmt.Println("\033[1;33m[ObjectKey]\033[0m", t.Token.Text)
-		return "ObjectKey"
-	case *ast.ObjectItem:
-		fmt.Println("\033[1;35m[ObjectItem]\033[0m", t.Keys[0].Token.Text)
-		return "ObjectItem"
-	case *ast.LiteralType:
-		fmt.Println("\033[1;33m[LiteralType]\033[0m", t.Token.Text)
-		return "LiteralType"
-	case *ast.ListType:
-		fmt.Println("\033[1;33m[ListType]\033[0m", t.List)
-		return "ListType"
-	case *ast.ObjectType:
-		fmt.Println("\033[1;36m[ObjectType]\033[0m", t.List.Items[0].Keys[0])
-		return "ObjectType"
-	default:
-		fmt.Printf(" unknown type: %T\n", n)
-		return "Unkown Type"
-	}
-}
-
 // objectType returns the printable HCL form of an object type. An object type
 // begins with a brace and ends with a brace.
 func (p *printer) objectType(o *ast.ObjectType, typeSchema map[string]interface{}) []byte {
@@ -833,6 +805,12 @@ func lines(txt string) int {
 // ----------------------------------------------------------------------------
 // Tracing support
 
+func (p *printer) logf(format string, v ...interface{}) {
+	if p.cfg.Logger != nil {
+		p.cfg.Logger.Printf(format, v...)
+	}
+}
+
 func (p *printer) printTrace(a ...interface{}) {
 	if !p.enableTrace {
 		return
@@ -0,0 +1,101 @@
+package printer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+)
+
+// Ordering controls the order of the items inside every block body.
+// Items named in First come first, then attributes, then nested blocks,
+// then items named in Last. Attributes named in Priority lead the
+// attributes in that order and the rest are sorted by name. Nested blocks
+// keep their relative order.
+type Ordering struct {
+	First    []string
+	Priority []string
+	Last     []string
+}
+
+// DefaultOrdering puts meta-arguments first and lifecycle/depends_on last.
+var DefaultOrdering = Ordering{
+	First: []string{"count", "for_each", "provider"},
+	Last:  []string{"lifecycle", "depends_on"},
+}
+
+const (
+	rankFirst = iota
+	rankAttribute
+	rankBlock
+	rankLast
+)
+
+// sortItems reorders the items of every object in node. The top level of
+// a file is left alone, since it holds top-level blocks rather than a body.
+func (o *Ordering) sortItems(node ast.Node, typeSchema map[string]interface{}) {
+	ast.Walk(node, func(nn ast.Node) (ast.Node, bool) {
+		if t, ok := nn.(*ast.ObjectType); ok {
+			items := t.List.Items
+			sort.SliceStable(items, func(i, j int) bool {
+				return o.less(items[i], items[j], typeSchema)
+			})
+		}
+		return nn, true
+	})
+}
+
+func (o *Ordering) less(a, b *ast.ObjectItem, typeSchema map[string]interface{}) bool {
+	rankA, indexA, nameA := o.rank(a, typeSchema)
+	rankB, indexB, nameB := o.rank(b, typeSchema)
+	switch {
+	case rankA != rankB:
+		return rankA < rankB
+	case indexA != indexB:
+		return indexA < indexB
+	case rankA == rankAttribute:
+		return nameA < nameB
+	default:
+		return false
+	}
+}
+
+// rank returns the group of item, its index in the list that put it there
+// (len of the list if none) and its name.
+func (o *Ordering) rank(item *ast.ObjectItem, typeSchema map[string]interface{}) (int, int, string) {
+	rawKey := strings.Replace(item.Keys[0].Token.Text, "\"", "", -1)
+	name := rawKey
+	if strings.Contains(name, "**##**") {
+		strSplit := strings.Split(name, "**##**")
+		name = strSplit[len(strSplit)-1]
+	}
+
+	if index := indexOf(o.First, name); index >= 0 {
+		return rankFirst, index, name
+	}
+	if index := indexOf(o.Last, name); index >= 0 {
+		return rankLast, index, name
+	}
+
+	objectType, _ := typeSchema[item.Keys[0].Token.Text].(string)
+	if objectType == "" {
+		objectType, _ = typeSchema[rawKey].(string)
+	}
+	_, isObject := item.Val.(*ast.ObjectType)
+	if objectType == "block" || objectType == "object" || (objectType == "" && isObject) || len(item.Keys) > 1 {
+		return rankBlock, 0, name
+	}
+	if index := indexOf(o.Priority, name); index >= 0 {
+		return rankAttribute, index, name
+	}
+	return rankAttribute, len(o.Priority), name
+}
+
+func indexOf(items []string, item string) int {
+	for i, v := range items {
+		if v == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -15,6 +15,18 @@ var DefaultConfig = Config{
 // A Config node controls the output of Fprint.
 type Config struct {
 	SpacesWidth int // if set, it will use spaces instead of tabs for alignment
+
+	// Ordering, if set, reorders the items of every block body before
+	// printing.
+	Ordering *Ordering
+
+	// Logger, if set, receives debug messages about the printed nodes.
+	Logger Logger
+}
+
+// Logger receives debug messages. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
 }
 
 func (c *Config) Fprint(output io.Writer, node ast.Node, typeSchema map[string]interface{}) error {
@@ -25,6 +37,10 @@ func (c *Config) Fprint(output io.Writer, node ast.Node, typeSchema map[string]i
 		// enableTrace:        true,
 	}
 
+	if c.Ordering != nil {
+		c.Ordering.sortItems(node, typeSchema)
+	}
+
 	p.collectComments(node)
 
 	if _, err := output.Write(p.unindent(p.output(node, typeSchema))); err != nil {